@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/simontheleg/konf-go/testhelper"
+)
+
+func TestParseAge(t *testing.T) {
+	tt := map[string]struct {
+		in     string
+		expAge time.Duration
+		expErr error
+	}{
+		"days":            {"30d", 30 * 24 * time.Hour, nil},
+		"hours":           {"12h", 12 * time.Hour, nil},
+		"combined":        {"1h30m", 90 * time.Minute, nil},
+		"negative days":   {"-1d", 0, fmt.Errorf("invalid age %q, use e.g. 30d or 12h", "-1d")},
+		"negative hours":  {"-1h", 0, fmt.Errorf("invalid age %q, use e.g. 30d or 12h", "-1h")},
+		"no unit":         {"30", 0, fmt.Errorf("invalid age %q, use e.g. 30d or 12h", "30")},
+		"fractional days": {"1.5d", 0, fmt.Errorf("invalid age %q, use e.g. 30d or 12h", "1.5d")},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			age, err := parseAge(tc.in)
+			if !testhelper.EqualError(tc.expErr, err) {
+				t.Errorf("Exp err %q, got %q", tc.expErr, err)
+			}
+			if age != tc.expAge {
+				t.Errorf("Exp age %v, got %v", tc.expAge, age)
+			}
+		})
+	}
+}
+
+func TestAgeValueString(t *testing.T) {
+	for in, exp := range map[string]string{"30d": "30d", "12h": "12h0m0s", "0d": "0s"} {
+		var a ageValue
+		if err := a.Set(in); err != nil {
+			t.Fatalf("Exp no error, got %v", err)
+		}
+		if a.String() != exp {
+			t.Errorf("Exp %q to be printed as %q, got %q", in, exp, a.String())
+		}
+	}
+}
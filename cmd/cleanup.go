@@ -1,12 +1,11 @@
 package cmd
 
 import (
-	"errors"
-	"io/fs"
+	"fmt"
 	"os"
-	"strconv"
+	"os/exec"
+	"time"
 
-	"github.com/mitchellh/go-ps"
 	"github.com/simontheleg/konf-go/config"
 	"github.com/simontheleg/konf-go/konf"
 	log "github.com/simontheleg/konf-go/log"
@@ -15,87 +14,253 @@ import (
 	"github.com/spf13/cobra"
 )
 
-// cleanupCmd represents the cleanup command
-var cleanupCmd = &cobra.Command{
-	Use:   "cleanup",
-	Short: "Cleanup inactive kubeconfigs",
-	Long: `This command cleans up any unused active configs (stored in konfDir/active).
-An active config is considered unused when no process points to it anymore`,
-	RunE: func(cmd *cobra.Command, args []string) error {
+type cleanupCmd struct {
+	sm      *store.Storemanager
+	isAlive func(konf.KonfID) (bool, error)
 
-		fs := afero.NewOsFs()
-		sm := &store.Storemanager{Activedir: config.ActiveDir(), Storedir: config.StoreDir(), Fs: fs}
+	dryRun    bool
+	olderThan ageValue
+	all       bool
+	// background is set for cleanups started by lazyCleanup
+	background bool
 
-		err := cleanLeftOvers(sm)
-		if err != nil {
-			return err
-		}
+	cmd *cobra.Command
+}
+
+func newCleanupCmd() *cleanupCmd {
+	fs := afero.NewOsFs()
+	sm := &store.Storemanager{Activedir: config.ActiveDir(), Storedir: config.StoreDir(), Statedir: config.StateDir(), Fs: fs}
+	cc := &cleanupCmd{
+		sm:      sm,
+		isAlive: sessionIsAlive,
+	}
+
+	cc.cmd = &cobra.Command{
+		Use:   "cleanup",
+		Short: "Cleanup inactive kubeconfigs",
+		Long: `This command cleans up any unused active configs (stored in konfDir/active).
+An active config is considered unused when no process points to it anymore.
+Without any flags, the active config of the current shell is removed as well, as
+this is what the shellwrapper runs when a shell is closed.
+
+Examples:
+-> 'cleanup --dry-run' print the active configs that would be removed without removing them
+-> 'cleanup --older-than 7d' additionally remove active configs that have not been changed for 7 days, even if their shell is still running
+-> 'cleanup --all' remove all active configs, including the ones of running shells
+`,
+		RunE: cc.cleanup,
+		Args: cobra.NoArgs,
+	}
+
+	cc.cmd.Flags().BoolVar(&cc.dryRun, "dry-run", false, "only print the active configs that would be removed (default is false)")
+	cc.cmd.Flags().Var(&cc.olderThan, "older-than", "additionally remove active configs that have not been changed for longer than this, e.g. 7d or 12h")
+	cc.cmd.Flags().BoolVar(&cc.all, "all", false, "remove all active configs, including the ones of running shells (default is false)")
+	// the shell that started a background cleanup is still running, so its konf must not be removed
+	cc.cmd.Flags().BoolVar(&cc.background, "background", false, "only remove active configs of closed shells and do not report anything")
+	cc.cmd.Flags().MarkHidden("background")
+
+	return cc
+}
+
+func (c *cleanupCmd) cleanup(cmd *cobra.Command, args []string) error {
+	if c.all && c.olderThan != 0 {
+		return fmt.Errorf("flags --all and --older-than cannot be used together")
+	}
+
+	if c.background {
+		_, err := cleanLeftOvers(c.sm, c.isAlive, cleanupOptions{}, time.Now())
+		return err
+	}
 
-		err = selfClean(sm)
-		if err != nil {
-			return err
+	opts := c.options()
+	// only a plain cleanup is run by the shellwrapper when the shell is closed
+	plain := !c.all && c.olderThan == 0
+
+	report, err := cleanLeftOvers(c.sm, c.isAlive, opts, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if c.dryRun {
+		for _, id := range report.Removed {
+			fmt.Println(id)
 		}
+	}
+	// closing a shell should not print anything, unless there is something to tell
+	if !plain || c.dryRun || len(report.Removed) > 1 || len(report.Failed) > 0 || (len(report.Removed) == 1 && report.Removed[0] != opts.self) {
+		log.Info("%s", report.summary(c.dryRun))
+	}
 
-		return nil
-	},
+	if len(report.Failed) > 0 {
+		return fmt.Errorf("could not clean up %d active config(s)", len(report.Failed))
+	}
+	return nil
 }
 
-// selfClean should just find its parent process and delete that file
-// it is required as the idempotent clean would delete all files that
-// do not belong to any process anymore, but of course the current process
-// is still running at this time
-func selfClean(sm *store.Storemanager) error {
-	pid := os.Getppid()
+// options returns the cleanupOptions for the supplied flags. A plain cleanup
+// additionally removes the session of the current shell, as this is what the
+// shellwrapper runs when the shell is closed. A dry run is run manually from
+// a shell that stays open, so it shows what an explicit cleanup would do
+// instead
+func (c *cleanupCmd) options() cleanupOptions {
+	opts := cleanupOptions{
+		dryRun:    c.dryRun,
+		olderThan: time.Duration(c.olderThan),
+		all:       c.all,
+	}
+	if !c.all && c.olderThan == 0 && !c.dryRun {
+		opts.self = currentSessionID()
+	}
+	return opts
+}
 
-	konfID := konf.IDFromProcessID(pid)
-	fpath := sm.ActivePathFromID(konfID)
-	err := sm.Fs.Remove(fpath)
+// lazyCleanup starts a cleanup of leftover active konfs in the background, if
+// the last one has been started more than interval ago. Cleaning up when a
+// shell is closed does not work for shells that have been killed, so this is
+// run on every 'konf set' instead. Failures are only logged, as they must
+// never stop a konf from being set
+func lazyCleanup(sm *store.Storemanager, interval time.Duration, now time.Time, start func() error) {
+	if interval <= 0 {
+		return
+	}
 
-	if errors.Is(err, fs.ErrNotExist) {
-		log.Info("current konf '%s' was already deleted, nothing to self-cleanup\n", fpath)
-		return nil
+	last, err := sm.LastCleanup()
+	if err != nil {
+		log.Warn("Could not read time of last cleanup: %v", err)
+		return
+	}
+	if now.Sub(last) < interval {
+		return
 	}
 
+	// the time is recorded first, so concurrent calls from other shells do not start a cleanup as well
+	if err := sm.SetLastCleanup(now); err != nil {
+		log.Warn("Could not save time of last cleanup: %v", err)
+		return
+	}
+	if err := start(); err != nil {
+		log.Warn("Could not start cleanup of leftover konfs: %v", err)
+	}
+}
+
+// startBackgroundCleanup runs 'konf cleanup --background' as a separate
+// process and returns without waiting for it, so setting a konf is not
+// delayed by the cleanup
+func startBackgroundCleanup() error {
+	exe, err := os.Executable()
 	if err != nil {
 		return err
 	}
 
-	return nil
+	// stdout must not be inherited, as the shellwrapper waits for it to be closed
+	// global flags have to come first, as they are parsed before the config is initialized, see initPersistentFlags
+	c := exec.Command(exe, "--konf-dir="+config.KonfDir(), "--silent", "cleanup", "--background")
+	if err := c.Start(); err != nil {
+		return err
+	}
+	return c.Process.Release()
+}
+
+// cleanupOptions controls which active konfs are removed by cleanLeftOvers
+type cleanupOptions struct {
+	dryRun bool
+	// olderThan additionally removes active konfs that have not been changed
+	// for longer than this, even if their session is still running
+	olderThan time.Duration
+	// all removes all active konfs, regardless of their session
+	all bool
+	// self is the session of the current shell. It is removed regardless of
+	// whether its shell is still running
+	self konf.KonfID
+}
+
+// cleanupReport summarizes what cleanLeftOvers did with every active konf
+type cleanupReport struct {
+	Removed []konf.KonfID
+	// Skipped are the active konfs of sessions that are still running
+	Skipped []konf.KonfID
+	// Unparseable are the active konfs that are not named after a process,
+	// so it cannot be determined whether their session is still running.
+	// They are only removed if olderThan or all say so, in which case they
+	// are reported as removed instead
+	Unparseable []konf.KonfID
+	Failed      map[konf.KonfID]error
+}
+
+// summary returns a single line describing the report
+func (r *cleanupReport) summary(dryRun bool) string {
+	removed := "Removed"
+	if dryRun {
+		removed = "Would remove"
+	}
+	return fmt.Sprintf("%s %d active config(s), skipped %d in use, %d unparseable, %d failed", removed, len(r.Removed), len(r.Skipped), len(r.Unparseable), len(r.Failed))
 }
 
 // cleanLeftOvers should look through the list of all processes that are available
-// and clean up any files that are not in use any more. It's main purpose is to clean-up
+// and clean up any files that are not in use any more or whose session has expired. It's main purpose is to clean-up
 // any leftovers that can occur if a previous session was not cleaned up nicely. This is
-// necessary as we cannot tell a user that a selfClean has failed if they close the shell
-// session before
-func cleanLeftOvers(sm *store.Storemanager) error {
+// necessary as we cannot tell a user that cleaning up has failed if they close the shell
+// session before.
+//
+// A single active konf that cannot be cleaned up does not stop the cleanup of
+// the others. Instead it is recorded as failed in the returned report
+func cleanLeftOvers(sm *store.Storemanager, isAlive func(konf.KonfID) (bool, error), opts cleanupOptions, now time.Time) (*cleanupReport, error) {
 	konfs, err := afero.ReadDir(sm.Fs, sm.Activedir)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	report := &cleanupReport{Failed: map[konf.KonfID]error{}}
 	for _, k := range konfs {
-		// We need to trim of the .yaml file extension to get to the PID
-		konfID := konf.IDFromFileInfo(k)
-		pid, err := strconv.Atoi(string(konfID))
-		if err != nil {
-			log.Warn("file '%s' could not be converted into an int, and therefore cannot be a valid process id. Skip for cleanup", k.Name())
+		if k.IsDir() {
 			continue
 		}
 
-		p, err := ps.FindProcess(pid)
-		if err != nil {
-			return err
+		// We need to trim of the .yaml file extension to get to the session ID
+		konfID := konf.IDFromFileInfo(k)
+		stale := opts.olderThan > 0 && now.Sub(k.ModTime()) > opts.olderThan
+		remove := opts.all || stale || konfID == opts.self
+
+		// expired sessions are removed, even if their shell is still running
+		if !remove {
+			ses, err := sm.Session(konfID)
+			if err != nil {
+				log.Warn("could not read session of file '%s': %v", k.Name(), err)
+				report.Failed[konfID] = err
+				continue
+			}
+			remove = ses != nil && ses.Expired(now)
 		}
 
-		if p == nil {
-			err := sm.Fs.Remove(sm.ActivePathFromID(konfID))
+		if !remove {
+			if _, _, err := konf.ProcessFromID(konfID); err != nil {
+				log.Warn("file '%s' is not named after a process id, so it cannot be determined whether its session is still running. Skip for cleanup", k.Name())
+				report.Unparseable = append(report.Unparseable, konfID)
+				continue
+			}
+
+			alive, err := isAlive(konfID)
 			if err != nil {
-				return err
+				log.Warn("could not determine whether the session of file '%s' is still running: %v", k.Name(), err)
+				report.Failed[konfID] = err
+				continue
+			}
+			if alive {
+				report.Skipped = append(report.Skipped, konfID)
+				continue
 			}
 		}
+
+		if !opts.dryRun {
+			if err := sm.RemoveSession(konfID); err != nil {
+				log.Warn("could not remove file '%s': %v", k.Name(), err)
+				report.Failed[konfID] = err
+				continue
+			}
+		}
+		report.Removed = append(report.Removed, konfID)
 	}
 
-	return nil
+	return report, nil
 }
@@ -8,7 +8,9 @@ import (
 	"os/exec"
 	"syscall"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/simontheleg/konf-go/konf"
 	"github.com/simontheleg/konf-go/store"
 	"github.com/simontheleg/konf-go/testhelper"
@@ -20,8 +22,6 @@ func TestSelfClean(t *testing.T) {
 	activeDir := "./konf/active"
 	storeDir := "./konf/store"
 
-	ppid := os.Getppid()
-
 	tt := map[string]struct {
 		Fs          afero.Fs
 		ExpError    error
@@ -32,7 +32,7 @@ func TestSelfClean(t *testing.T) {
 			ppidFS(activeDir),
 			nil,
 			[]string{activeDir + "/abc", activeDir + "/1234"},
-			[]string{activeDir + "/" + fmt.Sprint(ppid) + ".yaml"},
+			[]string{activeDir + "/" + string(currentSessionID()) + ".yaml"},
 		},
 		"PID file deleted by external source": {
 			ppidFileMissing(activeDir),
@@ -50,7 +50,8 @@ func TestSelfClean(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 
 			sm := &store.Storemanager{Fs: tc.Fs, Activedir: activeDir, Storedir: storeDir}
-			err := selfClean(sm)
+			alive := func(konf.KonfID) (bool, error) { return true, nil }
+			_, err := cleanLeftOvers(sm, alive, cleanupOptions{self: currentSessionID()}, time.Now())
 
 			if !testhelper.EqualError(err, tc.ExpError) {
 				t.Errorf("Want error '%s', got '%s'", tc.ExpError, err)
@@ -78,10 +79,9 @@ func TestSelfClean(t *testing.T) {
 }
 
 func ppidFS(activeDir string) afero.Fs {
-	ppid := os.Getppid()
 	fs := ppidFileMissing(activeDir)
 	sm := testhelper.SampleKonfManager{}
-	afero.WriteFile(fs, activeDir+"/"+fmt.Sprint(ppid), []byte(sm.SingleClusterSingleContextEU()), utils.KonfPerm)
+	afero.WriteFile(fs, activeDir+"/"+string(currentSessionID())+".yaml", []byte(sm.SingleClusterSingleContextEU()), utils.KonfPerm)
 	return fs
 }
 
@@ -126,7 +126,7 @@ func TestCleanLeftOvers(t *testing.T) {
 				cleanUpRunningCmds(t, cmdsRunning)
 			})
 
-			err := cleanLeftOvers(sm)
+			_, err := cleanLeftOvers(sm, sessionIsAlive, cleanupOptions{}, time.Now())
 
 			if !errors.Is(err, tc.ExpErr) {
 				t.Errorf("Want error '%s', got '%s'", tc.ExpErr, err)
@@ -165,6 +165,153 @@ func TestCleanLeftOvers(t *testing.T) {
 
 }
 
+func TestCleanLeftOversOptions(t *testing.T) {
+	now := time.Date(2022, 1, 10, 12, 0, 0, 0, time.UTC)
+	fresh, stale := now.Add(-time.Hour), now.Add(-10*24*time.Hour)
+	files := map[konf.KonfID]time.Time{
+		"100": fresh, "200": stale, "300": fresh, "400": fresh, "500": fresh, "vscode_1": fresh, "vscode_2": stale,
+	}
+	// session 300 has been closed, the state of session 400 cannot be determined and session 500 has expired.
+	// vscode_2 is not named after a process and has not been changed for 10 days
+	isAlive := func(id konf.KonfID) (bool, error) {
+		switch id {
+		case "300":
+			return false, nil
+		case "400":
+			return false, fmt.Errorf("ps failed")
+		}
+		return true, nil
+	}
+
+	tt := map[string]struct {
+		opts cleanupOptions
+		// expReport is compared without its failed konfs, which are compared to expFailed instead
+		expReport *cleanupReport
+		expFailed []konf.KonfID
+		expFiles  []konf.KonfID
+	}{
+		"only closed sessions": {
+			cleanupOptions{},
+			&cleanupReport{Removed: []konf.KonfID{"300", "500"}, Skipped: []konf.KonfID{"100", "200"}, Unparseable: []konf.KonfID{"vscode_1", "vscode_2"}},
+			[]konf.KonfID{"400"},
+			[]konf.KonfID{"100", "200", "400", "vscode_1", "vscode_2"},
+		},
+		"own session": {
+			cleanupOptions{self: "100"},
+			&cleanupReport{Removed: []konf.KonfID{"100", "300", "500"}, Skipped: []konf.KonfID{"200"}, Unparseable: []konf.KonfID{"vscode_1", "vscode_2"}},
+			[]konf.KonfID{"400"},
+			[]konf.KonfID{"200", "400", "vscode_1", "vscode_2"},
+		},
+		"dry run": {
+			cleanupOptions{dryRun: true},
+			&cleanupReport{Removed: []konf.KonfID{"300", "500"}, Skipped: []konf.KonfID{"100", "200"}, Unparseable: []konf.KonfID{"vscode_1", "vscode_2"}},
+			[]konf.KonfID{"400"},
+			[]konf.KonfID{"100", "200", "300", "400", "500", "vscode_1", "vscode_2"},
+		},
+		"older than": {
+			cleanupOptions{olderThan: 7 * 24 * time.Hour},
+			&cleanupReport{Removed: []konf.KonfID{"200", "300", "500", "vscode_2"}, Skipped: []konf.KonfID{"100"}, Unparseable: []konf.KonfID{"vscode_1"}},
+			[]konf.KonfID{"400"},
+			[]konf.KonfID{"100", "400", "vscode_1"},
+		},
+		"all": {
+			cleanupOptions{all: true},
+			&cleanupReport{Removed: []konf.KonfID{"100", "200", "300", "400", "500", "vscode_1", "vscode_2"}},
+			[]konf.KonfID{},
+			[]konf.KonfID{},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			sm := &store.Storemanager{Fs: afero.NewMemMapFs(), Activedir: "./konf/active", Statedir: "./konf/state"}
+			for id, mod := range files {
+				path := sm.ActivePathFromID(id)
+				if err := afero.WriteFile(sm.Fs, path, []byte{}, utils.KonfPerm); err != nil {
+					t.Fatal(err)
+				}
+				if err := sm.Fs.Chtimes(path, mod, mod); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := sm.WriteSession(&store.Session{ID: "500", Expires: now.Add(-time.Minute)}); err != nil {
+				t.Fatal(err)
+			}
+
+			report, err := cleanLeftOvers(sm, isAlive, tc.opts, now)
+			if err != nil {
+				t.Fatalf("Exp no error, got %v", err)
+			}
+
+			failed := []konf.KonfID{}
+			for id := range report.Failed {
+				failed = append(failed, id)
+			}
+			if diff := cmp.Diff(tc.expFailed, failed); diff != "" {
+				t.Errorf("Exp failed active konfs to match (-want +got):\n%s", diff)
+			}
+			report.Failed = nil
+			if diff := cmp.Diff(tc.expReport, report); diff != "" {
+				t.Errorf("Exp report to match (-want +got):\n%s", diff)
+			}
+
+			remaining := []konf.KonfID{}
+			fis, _ := afero.ReadDir(sm.Fs, sm.Activedir)
+			for _, fi := range fis {
+				remaining = append(remaining, konf.IDFromFileInfo(fi))
+			}
+			if diff := cmp.Diff(tc.expFiles, remaining); diff != "" {
+				t.Errorf("Exp remaining active konfs to match (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCleanupCmdOptions(t *testing.T) {
+	t.Setenv(sessionEnv, "shell_1")
+
+	tt := map[string]struct {
+		cc  *cleanupCmd
+		exp cleanupOptions
+	}{
+		"plain cleanup removes own session": {
+			&cleanupCmd{},
+			cleanupOptions{self: "shell_1"},
+		},
+		"dry run does not include own session": {
+			&cleanupCmd{dryRun: true},
+			cleanupOptions{dryRun: true},
+		},
+		"older than": {
+			&cleanupCmd{olderThan: ageValue(time.Hour)},
+			cleanupOptions{olderThan: time.Hour},
+		},
+		"all": {
+			&cleanupCmd{all: true},
+			cleanupOptions{all: true},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if res := tc.cc.options(); res != tc.exp {
+				t.Errorf("Exp options %+v, got %+v", tc.exp, res)
+			}
+		})
+	}
+}
+
+func TestCleanupReportSummary(t *testing.T) {
+	r := &cleanupReport{Removed: []konf.KonfID{"1", "2"}, Skipped: []konf.KonfID{"3"}, Failed: map[konf.KonfID]error{}}
+
+	if exp, got := "Removed 2 active config(s), skipped 1 in use, 0 unparseable, 0 failed", r.summary(false); got != exp {
+		t.Errorf("Exp summary %q, got %q", exp, got)
+	}
+	if exp, got := "Would remove 2 active config(s), skipped 1 in use, 0 unparseable, 0 failed", r.summary(true); got != exp {
+		t.Errorf("Exp summary %q, got %q", exp, got)
+	}
+}
+
 func mixedFSWithAllProcs(t *testing.T, sm *store.Storemanager) (cmdsRunning []*exec.Cmd, cmdsStopped []*exec.Cmd) {
 	// we are simulating other instances of konf here
 	numOfConfs := 3
@@ -251,3 +398,84 @@ func cleanUpRunningCmds(t *testing.T, cmds []*exec.Cmd) {
 		t.Fatalf("Cleanup went wrong, please manually check the following processes: %v", rogueProcesses)
 	}
 }
+
+func TestSessionIsAlive(t *testing.T) {
+	pid := os.Getpid()
+	start, err := utils.ProcessStartTime(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start == "" {
+		t.Skip("Skipping TestSessionIsAlive, as process start times are not available")
+	}
+
+	exited := exec.Command("true")
+	if err := exited.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	tt := map[string]struct {
+		id  konf.KonfID
+		exp bool
+	}{
+		"running process":       {konf.IDFromProcess(pid, start), true},
+		"pid has been reused":   {konf.IDFromProcess(pid, start+"1"), false},
+		"session without start": {konf.IDFromProcessID(pid), true},
+		"process has exited":    {konf.IDFromProcess(exited.Process.Pid, start), false},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			alive, err := sessionIsAlive(tc.id)
+			if err != nil {
+				t.Fatalf("Exp no error, got %v", err)
+			}
+			if alive != tc.exp {
+				t.Errorf("Exp session %q to be alive: %t, got %t", tc.id, tc.exp, alive)
+			}
+		})
+	}
+}
+
+func TestLazyCleanup(t *testing.T) {
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tt := map[string]struct {
+		last     time.Time
+		interval time.Duration
+		startErr error
+		expStart bool
+		expLast  time.Time
+	}{
+		"never cleaned up":       {time.Time{}, time.Hour, nil, true, now},
+		"cleaned up recently":    {now.Add(-time.Minute), time.Hour, nil, false, now.Add(-time.Minute)},
+		"interval has passed":    {now.Add(-2 * time.Hour), time.Hour, nil, true, now},
+		"disabled":               {time.Time{}, 0, nil, false, time.Time{}},
+		"cleanup fails to start": {time.Time{}, time.Hour, fmt.Errorf("no executable"), true, now},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			sm := &store.Storemanager{Fs: afero.NewMemMapFs(), Statedir: "./konf/state"}
+			if !tc.last.IsZero() {
+				if err := sm.SetLastCleanup(tc.last); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			started := false
+			lazyCleanup(sm, tc.interval, now, func() error { started = true; return tc.startErr })
+
+			if started != tc.expStart {
+				t.Errorf("Exp cleanup to be started: %t, got %t", tc.expStart, started)
+			}
+			last, err := sm.LastCleanup()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !last.Equal(tc.expLast) {
+				t.Errorf("Exp last cleanup to be %v, got %v", tc.expLast, last)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/simontheleg/konf-go/config"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/log"
+	"github.com/simontheleg/konf-go/prompt"
+	"github.com/simontheleg/konf-go/store"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+type currentCmd struct {
+	fs afero.Fs
+	sm *store.Storemanager
+
+	protected bool
+
+	cmd *cobra.Command
+}
+
+func newCurrentCmd() *currentCmd {
+	fs := afero.NewOsFs()
+	sm := &store.Storemanager{Fs: fs, Activedir: config.ActiveDir(), Storedir: config.StoreDir(), Statedir: config.StateDir()}
+
+	cc := &currentCmd{
+		fs: fs,
+		sm: sm,
+	}
+
+	cc.cmd = &cobra.Command{
+		Use:   "current",
+		Short: "Print konf used in current shell",
+		Long: `Print the ID of the konf that is used in the current shell.
+
+If multiple konfs have been merged into the current shell, all of their IDs
+are printed. The primary konf is always printed first. If the konf expires,
+the remaining time is logged as well.`,
+		RunE: cc.current,
+		Args: cobra.ExactArgs(0),
+	}
+
+	cc.cmd.Flags().BoolVar(&cc.protected, "protected", false, "print whether any konf used in the current shell is protected instead of their IDs (default is false)")
+
+	return cc
+}
+
+func (c *currentCmd) current(cmd *cobra.Command, args []string) error {
+	ids, err := currentKonfIDs(c.fs)
+	if err != nil {
+		return err
+	}
+
+	if c.protected {
+		protected, err := anyProtected(c.sm, ids)
+		if err != nil {
+			return err
+		}
+		fmt.Println(protected)
+		return nil
+	}
+
+	for _, id := range ids {
+		fmt.Println(id)
+	}
+
+	// the remaining time is logged instead of printed, so scripts can keep using the output of current
+	kPath, err := kubeconfigEnv()
+	if err != nil {
+		return err
+	}
+	ses, err := sessionOfKubeconfig(c.sm, kPath)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if ses != nil && ses.Expired(now) {
+		log.Warn("The konf of this shell has expired. Run 'konf set' to use a konf again")
+	} else if ses != nil && !ses.Expires.IsZero() {
+		log.Info("The konf of this shell expires in %s", prompt.TimeLeft(ses.Expires, now))
+	}
+
+	return nil
+}
+
+// anyProtected returns true if any of the konfs with the supplied ids is
+// protected
+func anyProtected(sm *store.Storemanager, ids []konf.KonfID) (bool, error) {
+	for _, id := range ids {
+		m, err := sm.Meta(id)
+		if err != nil {
+			return false, err
+		}
+		if m.Protected {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// sessionOfKubeconfig returns the recorded session the kubeconfig at kPath
+// belongs to. If kPath is not an active konf or its session has not been
+// recorded, nil is returned
+func sessionOfKubeconfig(sm *store.Storemanager, kPath string) (*store.Session, error) {
+	id, ok := sessionFromKubeconfig(sm, kPath)
+	if !ok {
+		return nil, nil
+	}
+	return sm.Session(id)
+}
+
+// currentKonfIDs returns the IDs of all konfs in the active kubeconfig of the
+// current shell. The ID of the primary konf comes first
+func currentKonfIDs(fs afero.Fs) ([]konf.KonfID, error) {
+	conf, kPath, err := activeKubeconfig(fs)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := konf.IDsFromKubeconfig(conf)
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("kubeconfig %q does not contain any context", kPath)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/testhelper"
+	"github.com/spf13/afero"
+)
+
+func TestCurrentKonfIDs(t *testing.T) {
+	storeDir := "./konf/store"
+	activeDir := "./konf/active"
+	fm := testhelper.FilesystemManager{Storedir: storeDir, Activedir: activeDir}
+
+	tt := map[string]struct {
+		kubeenv   string
+		FSCreator func() afero.Fs
+		ExpIDs    []konf.KonfID
+		ExpErr    bool
+	}{
+		"no $KUBECONFIG set": {
+			"",
+			testhelper.FSWithFiles(),
+			nil,
+			true,
+		},
+		"single konf": {
+			"./konf/active/dev-eu_dev-eu-1.yaml",
+			testhelper.FSWithFiles(fm.ActiveDir, fm.SingleClusterSingleContextEU),
+			[]konf.KonfID{"dev-eu_dev-eu-1"},
+			false,
+		},
+		"konf without context": {
+			"./konf/active/no-context.yaml",
+			testhelper.FSWithFiles(fm.ActiveDir, fm.KonfWithoutContext),
+			nil,
+			true,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("KUBECONFIG", tc.kubeenv)
+
+			res, err := currentKonfIDs(tc.FSCreator())
+
+			if (err != nil) != tc.ExpErr {
+				t.Errorf("Exp error to be %t, got %v", tc.ExpErr, err)
+			}
+
+			if !cmp.Equal(res, tc.ExpIDs) {
+				t.Errorf("Exp ids %v, got %v", tc.ExpIDs, res)
+			}
+		})
+	}
+}
@@ -1,6 +1,11 @@
 package cmd
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/simontheleg/konf-go/config"
 	"github.com/simontheleg/konf-go/konf"
 	"github.com/simontheleg/konf-go/log"
@@ -13,24 +18,31 @@ import (
 type deleteCmd struct {
 	sm               *store.Storemanager
 	fetchconfs       func() ([]*store.Metadata, error)
-	selectSingleKonf func(*store.Storemanager, prompt.RunFunc) (konf.KonfID, error)
+	selectKonfs      func(*store.Storemanager, prompt.MultiRunFunc) ([]konf.KonfID, error)
 	deleteKonfWithID func(*store.Storemanager, konf.KonfID) error
 	idsForGlobs      func(*store.Storemanager, []string) ([]konf.KonfID, error)
-	prompt           prompt.RunFunc
+	prompt           prompt.MultiRunFunc
+	confirm          prompt.ConfirmFunc
+	isTerminal       func() bool
+
+	dryRun bool
+	yes    bool
 
 	cmd *cobra.Command
 }
 
 func newDeleteCommand() *deleteCmd {
 	fs := afero.NewOsFs()
-	sm := &store.Storemanager{Fs: fs, Activedir: config.ActiveDir(), Storedir: config.StoreDir()}
+	sm := &store.Storemanager{Fs: fs, Activedir: config.ActiveDir(), Storedir: config.StoreDir(), Statedir: config.StateDir(), Trashdir: config.TrashDir()}
 	dc := &deleteCmd{
 		sm:               sm,
 		fetchconfs:       sm.FetchAllKonfs,
-		selectSingleKonf: selectSingleKonf,
+		selectKonfs:      selectKonfsForDeletion,
 		deleteKonfWithID: deleteKonfWithID,
 		idsForGlobs:      idsForGlobs,
-		prompt:           prompt.Terminal,
+		prompt:           prompt.ConfiguredMulti(),
+		confirm:          prompt.Confirm,
+		isTerminal:       prompt.IsTerminal,
 	}
 
 	dc.cmd = &cobra.Command{
@@ -38,15 +50,25 @@ func newDeleteCommand() *deleteCmd {
 		Short: "Delete kubeconfig",
 		Long: `Delete one or multiple kubeconfigs
 
+Deleted konfs are moved to the trash, from where they can be restored using
+'konf restore'
+
 Examples:
--> 'delete' run selection prompt for deletion
+-> 'delete' run selection prompt for deletion. Multiple konfs can be selected
 -> 'delete <konfig id> [<konfig id 2>]' delete specific konf(s)
 -> 'delete "my-konf*"' delete konf matching fileglob
+-> 'delete "my-konf*" --dry-run' print the konfs matching fileglob without deleting them
+
+Deleting multiple konfs has to be confirmed. When not run interactively,
+multiple konfs are only deleted if --yes is given
 `,
 		RunE:              dc.delete,
 		ValidArgsFunction: dc.completeDelete,
 	}
 
+	dc.cmd.Flags().BoolVar(&dc.dryRun, "dry-run", false, "only print the konfs that would be deleted (default is false)")
+	dc.cmd.Flags().BoolVarP(&dc.yes, "yes", "y", false, "delete without asking for confirmation (default is false)")
+
 	return dc
 }
 
@@ -54,18 +76,45 @@ func (c *deleteCmd) delete(cmd *cobra.Command, args []string) error {
 	var ids []konf.KonfID
 	var err error
 
+	// a selection in the picker is always confirmed, as it is easy to toggle one konf too many
+	needsConfirmation := true
 	if len(args) == 0 {
-		var id konf.KonfID
-		id, err = c.selectSingleKonf(c.sm, c.prompt)
+		ids, err = c.selectKonfs(c.sm, c.prompt)
 		if err != nil {
 			return err
 		}
-		ids = append(ids, id)
+		if len(ids) == 0 {
+			log.Info("No konf selected, nothing to delete")
+			return nil
+		}
 	} else {
 		ids, err = c.idsForGlobs(c.sm, args)
 		if err != nil {
 			return err
 		}
+		needsConfirmation = len(ids) > 1
+	}
+
+	if c.dryRun {
+		for _, id := range ids {
+			fmt.Println(id)
+		}
+		return nil
+	}
+
+	if needsConfirmation && !c.yes {
+		if !c.isTerminal() {
+			return fmt.Errorf("refusing to delete %d konfs without confirmation, use --yes to delete them anyway", len(ids))
+		}
+
+		ok, err := confirmDeletion(ids, c.confirm)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.Info("Deletion aborted")
+			return nil
+		}
 	}
 
 	for _, id := range ids {
@@ -78,12 +127,59 @@ func (c *deleteCmd) delete(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// selectKonfsForDeletion lets the user select any number of konfs from the
+// store
+func selectKonfsForDeletion(sm *store.Storemanager, pf prompt.MultiRunFunc) ([]konf.KonfID, error) {
+	k, err := sm.FetchAllKonfs()
+	if err != nil {
+		return nil, err
+	}
+
+	// not having an active konf is perfectly fine. In that case there is just nothing to mark
+	if ids, err := currentKonfIDs(sm.Fs); err == nil {
+		markActiveKonfs(k, ids)
+	}
+
+	p := createSetPrompt(k)
+	// the multi-select prompt tracks the selection by position, so konfs must not be reordered while searching
+	p.Searcher = func(input string, index int) bool {
+		return prompt.FuzzyFilterKonf(input, k[index])
+	}
+
+	selPos, err := pf(p)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []konf.KonfID{}
+	for _, pos := range selPos {
+		if pos < 0 || pos >= len(k) {
+			return nil, fmt.Errorf("invalid selection %d", pos)
+		}
+		ids = append(ids, konf.IDFromClusterAndContext(k[pos].Cluster, k[pos].Context))
+	}
+	return ids, nil
+}
+
+// confirmDeletion lists all ids that are about to be deleted and asks the user
+// to confirm
+func confirmDeletion(ids []konf.KonfID, confirm prompt.ConfirmFunc) (bool, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The following %d konf(s) will be deleted:", len(ids))
+	for _, id := range ids {
+		fmt.Fprintf(&b, "\n  %s", id)
+	}
+	log.Info("%s", b.String())
+
+	return confirm(fmt.Sprintf("Delete %d konf(s)", len(ids)))
+}
+
+// deleteKonfWithID moves the konf with the supplied id to the trash
 func deleteKonfWithID(sm *store.Storemanager, id konf.KonfID) error {
-	path := sm.StorePathFromID(id)
-	if err := sm.Fs.Remove(path); err != nil {
+	if _, err := sm.Trash(id, time.Now()); err != nil {
 		return err
 	}
-	log.Info("Successfully deleted konf %q at %q", id, path)
+	log.Info("Moved konf %q to the trash. It can be restored using 'konf restore %s'", id, id)
 	return nil
 }
 
@@ -98,7 +194,10 @@ func idsForGlobs(sm *store.Storemanager, patterns []string) ([]konf.KonfID, erro
 		}
 		for _, f := range metadata {
 			id := konf.IDFromClusterAndContext(f.Cluster, f.Context)
-			ids = append(ids, id)
+			// patterns can overlap, but every konf can only be deleted once
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
 		}
 	}
 	return ids, nil
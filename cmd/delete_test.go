@@ -2,11 +2,13 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
 	"io/fs"
 	"sort"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/manifoldco/promptui"
 	"github.com/simontheleg/konf-go/konf"
 	"github.com/simontheleg/konf-go/prompt"
 	"github.com/simontheleg/konf-go/store"
@@ -19,6 +21,8 @@ import (
 func TestDeleteKonfWithID(t *testing.T) {
 	storeDir := "./konf/store"
 	activeDir := "./konf/active"
+	stateDir := "./konf/state"
+	trashDir := "./konf/trash"
 	fm := testhelper.FilesystemManager{Storedir: storeDir, Activedir: activeDir}
 
 	tt := map[string]struct {
@@ -27,6 +31,7 @@ func TestDeleteKonfWithID(t *testing.T) {
 		expError    error
 		expFiles    []string
 		notExpFiles []string
+		expTrashed  []konf.KonfID
 	}{
 		"file was found": {
 			fsCreator:   testhelper.FSWithFiles(fm.SingleClusterSingleContextEU, fm.SingleClusterSingleContextASIA),
@@ -34,6 +39,7 @@ func TestDeleteKonfWithID(t *testing.T) {
 			expError:    nil,
 			expFiles:    []string{storeDir + "/dev-asia_dev-asia-1.yaml"},
 			notExpFiles: []string{storeDir + "/dev-eu_dev-eu-1.yaml"},
+			expTrashed:  []konf.KonfID{"dev-eu_dev-eu-1"},
 		},
 		"file was not found": {
 			fsCreator:   testhelper.FSWithFiles(fm.SingleClusterSingleContextASIA),
@@ -41,6 +47,7 @@ func TestDeleteKonfWithID(t *testing.T) {
 			expError:    fs.ErrNotExist,
 			expFiles:    []string{storeDir + "/dev-asia_dev-asia-1.yaml"},
 			notExpFiles: []string{},
+			expTrashed:  []konf.KonfID{},
 		},
 	}
 
@@ -48,7 +55,7 @@ func TestDeleteKonfWithID(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 
 			fsm := tc.fsCreator()
-			sm := &store.Storemanager{Fs: fsm, Activedir: activeDir, Storedir: storeDir}
+			sm := &store.Storemanager{Fs: fsm, Activedir: activeDir, Storedir: storeDir, Statedir: stateDir, Trashdir: trashDir}
 
 			err := deleteKonfWithID(sm, tc.idToDelete)
 
@@ -74,6 +81,18 @@ func TestDeleteKonfWithID(t *testing.T) {
 				}
 			}
 
+			trashed, err := sm.TrashedKonfs()
+			if err != nil {
+				t.Fatalf("An unexpected error has occurred: %q", err)
+			}
+			ids := []konf.KonfID{}
+			for _, tk := range trashed {
+				ids = append(ids, tk.ID)
+			}
+			if !cmp.Equal(tc.expTrashed, ids) {
+				t.Errorf("Exp trashed konfs %v, got %v", tc.expTrashed, ids)
+			}
+
 		})
 	}
 }
@@ -119,6 +138,12 @@ func TestIDsForGlobs(t *testing.T) {
 			expIDs:    []string{"dev-eu_dev-eu-1", "dev-eu_dev-eu-2", "dev-asia_dev-asia-1", "dev-asia_dev-asia-2"},
 			expError:  nil,
 		},
+		"overlapping arguments": {
+			fsCreator: testhelper.FSWithFiles(fm.SingleClusterSingleContextEU, fm.SingleClusterSingleContextASIA, fm.SingleClusterSingleContextEU2, fm.SingleClusterSingleContextASIA2),
+			patterns:  []string{"dev-eu_dev-eu*", "dev-eu_dev-eu-1"},
+			expIDs:    []string{"dev-eu_dev-eu-1", "dev-eu_dev-eu-2"},
+			expError:  nil,
+		},
 		"no match": {
 			fsCreator: testhelper.FSWithFiles(fm.SingleClusterSingleContextEU, fm.SingleClusterSingleContextASIA, fm.SingleClusterSingleContextEU2, fm.SingleClusterSingleContextASIA2),
 			patterns:  []string{"no-match"},
@@ -157,18 +182,29 @@ func TestIDsForGlobs(t *testing.T) {
 
 func TestDelete(t *testing.T) {
 
-	selectSingleKonfCalled := 0
+	selectKonfsCalled := 0
 	idsForGlobsCalled := 0
 	deleteKonfWithIDCalled := 0
+	confirmCalled := 0
+
+	var mockSelectKonfs = func(*store.Storemanager, prompt.MultiRunFunc) ([]konf.KonfID, error) {
+		selectKonfsCalled++
+		return []konf.KonfID{"id1", "id2"}, nil
+	}
 
-	var mockSelectSingleKonf = func(*store.Storemanager, prompt.RunFunc) (konf.KonfID, error) {
-		selectSingleKonfCalled++
-		return "id1", nil
+	var mockSelectNoKonfs = func(*store.Storemanager, prompt.MultiRunFunc) ([]konf.KonfID, error) {
+		selectKonfsCalled++
+		return []konf.KonfID{}, nil
 	}
 
-	var mockIDsForGlobs = func(*store.Storemanager, []string) ([]konf.KonfID, error) {
+	// every argument matches exactly one konf
+	var mockIDsForGlobs = func(_ *store.Storemanager, patterns []string) ([]konf.KonfID, error) {
 		idsForGlobsCalled++
-		return []konf.KonfID{"id1", "id2", "id3"}, nil
+		ids := []konf.KonfID{}
+		for _, p := range patterns {
+			ids = append(ids, konf.KonfID(p))
+		}
+		return ids, nil
 	}
 
 	var mockDeleteKonfWithID = func(*store.Storemanager, konf.KonfID) error {
@@ -176,61 +212,196 @@ func TestDelete(t *testing.T) {
 		return nil
 	}
 
-	cmd := &deleteCmd{
-		selectSingleKonf: mockSelectSingleKonf,
-		idsForGlobs:      mockIDsForGlobs,
-		deleteKonfWithID: mockDeleteKonfWithID,
+	var mockConfirm = func(answer bool) prompt.ConfirmFunc {
+		return func(string) (bool, error) {
+			confirmCalled++
+			return answer, nil
+		}
+	}
+
+	type calls struct {
+		selectKonfs      int
+		idsForGlobs      int
+		confirm          int
+		deleteKonfWithID int
 	}
 
 	tt := map[string]struct {
-		args                      []string
-		expSelectSingleKonfCalled int
-		expIdsForGlobsCalled      int
-		expDeleteKonfWithIDCalled int
+		args        []string
+		selectKonfs func(*store.Storemanager, prompt.MultiRunFunc) ([]konf.KonfID, error)
+		confirm     bool
+		isTerminal  bool
+		dryRun      bool
+		yes         bool
+		expCalls    calls
+		expErr      error
 	}{
-		"select one": {
-			args:                      []string{},
-			expSelectSingleKonfCalled: 1,
-			expIdsForGlobsCalled:      0,
-			expDeleteKonfWithIDCalled: 1,
+		"select multiple": {
+			args:        []string{},
+			selectKonfs: mockSelectKonfs,
+			confirm:     true,
+			isTerminal:  true,
+			expCalls:    calls{selectKonfs: 1, confirm: 1, deleteKonfWithID: 2},
 		},
-		"multiple arguments supplied": {
-			args:                      []string{"id1", "id2", "id3"},
-			expSelectSingleKonfCalled: 0,
-			expIdsForGlobsCalled:      1,
-			expDeleteKonfWithIDCalled: 3,
+		"selection not confirmed": {
+			args:        []string{},
+			selectKonfs: mockSelectKonfs,
+			confirm:     false,
+			isTerminal:  true,
+			expCalls:    calls{selectKonfs: 1, confirm: 1},
+		},
+		"selection with yes": {
+			args:        []string{},
+			selectKonfs: mockSelectKonfs,
+			isTerminal:  true,
+			yes:         true,
+			expCalls:    calls{selectKonfs: 1, deleteKonfWithID: 2},
+		},
+		"nothing selected": {
+			args:        []string{},
+			selectKonfs: mockSelectNoKonfs,
+			confirm:     true,
+			isTerminal:  true,
+			expCalls:    calls{selectKonfs: 1},
+		},
+		"single argument needs no confirmation": {
+			args:       []string{"id1"},
+			isTerminal: false,
+			expCalls:   calls{idsForGlobs: 1, deleteKonfWithID: 1},
+		},
+		"multiple arguments confirmed": {
+			args:       []string{"id1", "id2", "id3"},
+			confirm:    true,
+			isTerminal: true,
+			expCalls:   calls{idsForGlobs: 1, confirm: 1, deleteKonfWithID: 3},
+		},
+		"multiple arguments not confirmed": {
+			args:       []string{"id1", "id2", "id3"},
+			confirm:    false,
+			isTerminal: true,
+			expCalls:   calls{idsForGlobs: 1, confirm: 1},
+		},
+		"multiple arguments with yes": {
+			args:       []string{"id1", "id2", "id3"},
+			isTerminal: true,
+			yes:        true,
+			expCalls:   calls{idsForGlobs: 1, deleteKonfWithID: 3},
+		},
+		"multiple arguments without terminal": {
+			args:       []string{"id1", "id2", "id3"},
+			isTerminal: false,
+			expCalls:   calls{idsForGlobs: 1},
+			expErr:     fmt.Errorf("refusing to delete 3 konfs without confirmation, use --yes to delete them anyway"),
+		},
+		"multiple arguments without terminal with yes": {
+			args:       []string{"id1", "id2", "id3"},
+			isTerminal: false,
+			yes:        true,
+			expCalls:   calls{idsForGlobs: 1, deleteKonfWithID: 3},
+		},
+		"dry run": {
+			args:       []string{"id1", "id2", "id3"},
+			isTerminal: true,
+			dryRun:     true,
+			expCalls:   calls{idsForGlobs: 1},
 		},
 	}
 
 	for name, tc := range tt {
 		t.Run(name, func(t *testing.T) {
 
-			selectSingleKonfCalled = 0
+			selectKonfsCalled = 0
 			idsForGlobsCalled = 0
 			deleteKonfWithIDCalled = 0
+			confirmCalled = 0
+
+			cmd := &deleteCmd{
+				selectKonfs:      tc.selectKonfs,
+				idsForGlobs:      mockIDsForGlobs,
+				deleteKonfWithID: mockDeleteKonfWithID,
+				confirm:          mockConfirm(tc.confirm),
+				isTerminal:       func() bool { return tc.isTerminal },
+				dryRun:           tc.dryRun,
+				yes:              tc.yes,
+			}
 
 			err := cmd.delete(cmd.cmd, tc.args)
 
-			if err != nil {
-				t.Fatalf("An unexpected error occured: %v", err)
+			if !testhelper.EqualError(tc.expErr, err) {
+				t.Errorf("Exp error %q, got %q", tc.expErr, err)
 			}
 
-			if tc.expSelectSingleKonfCalled != selectSingleKonfCalled {
-				t.Errorf("Exp SelectSingleKonf to be called %d times, was called %d times", tc.expSelectSingleKonfCalled, selectSingleKonfCalled)
+			res := calls{selectKonfsCalled, idsForGlobsCalled, confirmCalled, deleteKonfWithIDCalled}
+			if tc.expCalls != res {
+				t.Errorf("Exp calls %+v, got %+v", tc.expCalls, res)
 			}
 
-			if tc.expIdsForGlobsCalled != idsForGlobsCalled {
-				t.Errorf("Exp IDsForGlobsCalled to be called %d times, was called %d times", tc.expIdsForGlobsCalled, idsForGlobsCalled)
-			}
+		})
 
-			if tc.expDeleteKonfWithIDCalled != deleteKonfWithIDCalled {
-				t.Errorf("Exp DeleteKonfWithID to be called %d times, was called %d times", tc.expDeleteKonfWithIDCalled, deleteKonfWithIDCalled)
-			}
+	}
+
+}
+
+func TestSelectKonfsForDeletion(t *testing.T) {
+	storeDir := "./konf/store"
+	activeDir := "./konf/active"
+	fm := testhelper.FilesystemManager{Storedir: storeDir, Activedir: activeDir}
+
+	tt := map[string]struct {
+		pf     prompt.MultiRunFunc
+		expIDs []konf.KonfID
+		expErr error
+	}{
+		"select two": {
+			func(*promptui.Select) ([]int, error) { return []int{0, 1}, nil },
+			[]konf.KonfID{"dev-asia_dev-asia-1", "dev-eu_dev-eu-1"},
+			nil,
+		},
+		"select none": {
+			func(*promptui.Select) ([]int, error) { return []int{}, nil },
+			[]konf.KonfID{},
+			nil,
+		},
+		"invalid selection": {
+			func(*promptui.Select) ([]int, error) { return []int{0, 2}, nil },
+			nil,
+			fmt.Errorf("invalid selection 2"),
+		},
+	}
 
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			fs := testhelper.FSWithFiles(fm.SingleClusterSingleContextASIA, fm.SingleClusterSingleContextEU)()
+			sm := &store.Storemanager{Activedir: activeDir, Storedir: storeDir, Fs: fs}
+
+			ids, err := selectKonfsForDeletion(sm, tc.pf)
+			if !testhelper.EqualError(tc.expErr, err) {
+				t.Errorf("Exp error %q, got %q", tc.expErr, err)
+			}
+			if !cmp.Equal(tc.expIDs, ids) {
+				t.Errorf("Exp ids %v, got %v", tc.expIDs, ids)
+			}
 		})
+	}
+}
 
+func TestConfirmDeletion(t *testing.T) {
+	var label string
+	confirm := func(l string) (bool, error) {
+		label = l
+		return true, nil
 	}
 
+	ok, err := confirmDeletion([]konf.KonfID{"id1", "id2"}, confirm)
+	if err != nil {
+		t.Fatalf("Exp no error, got %v", err)
+	}
+	if !ok {
+		t.Errorf("Exp deletion to be confirmed")
+	}
+	if expLabel := "Delete 2 konf(s)"; label != expLabel {
+		t.Errorf("Exp label %q, got %q", expLabel, label)
+	}
 }
 
 func TestCompleteDelete(t *testing.T) {
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/simontheleg/konf-go/config"
+	"github.com/simontheleg/konf-go/konf"
+	log "github.com/simontheleg/konf-go/log"
+	"github.com/simontheleg/konf-go/prompt"
+	"github.com/simontheleg/konf-go/store"
+	"github.com/simontheleg/konf-go/utils"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	k8s "k8s.io/client-go/tools/clientcmd/api/v1"
+	"sigs.k8s.io/yaml"
+)
+
+type diffCmd struct {
+	sm         *store.Storemanager
+	confirm    prompt.ConfirmFunc
+	isTerminal func() bool
+
+	active    bool
+	writeBack bool
+	yes       bool
+
+	cmd *cobra.Command
+}
+
+func newDiffCmd() *diffCmd {
+	fs := afero.NewOsFs()
+	sm := &store.Storemanager{Fs: fs, Activedir: config.ActiveDir(), Storedir: config.StoreDir(), Statedir: config.StateDir()}
+	dc := &diffCmd{
+		sm:         sm,
+		confirm:    prompt.Confirm,
+		isTerminal: prompt.IsTerminal,
+	}
+
+	dc.cmd = &cobra.Command{
+		Use:   "diff",
+		Short: "Show how the kubeconfig of the current shell differs from the store",
+		Long: `Show every value in which the active konf of the current shell differs from the konf in the store.
+Tools like 'kubectl config set-context' modify the active konf, so these changes are lost once another konf is set.
+Values containing credentials are redacted.
+
+Paths limit the diff to the values at or below them. With --write-back, the shown changes are written to the konf in the store.
+Writing back has to be confirmed. When not run interactively, changes are only written back if --yes is given.
+Without --write-back, the diff shows exactly what would be written back.
+Changes that add, remove or rename clusters, contexts or users cannot be written back.
+
+Examples:
+-> 'diff --active' show all changes of the active konf
+-> 'diff --active users' only show changes of users
+-> 'diff --active contexts[dev-eu].context.namespace --write-back' make the namespace of the current shell the default namespace of the konf
+`,
+		RunE: dc.diff,
+	}
+
+	dc.cmd.Flags().BoolVar(&dc.active, "active", false, "compare the active konf of the current shell with the store")
+	dc.cmd.Flags().BoolVar(&dc.writeBack, "write-back", false, "write the shown changes to the konf in the store (default is false)")
+	dc.cmd.Flags().BoolVarP(&dc.yes, "yes", "y", false, "write back without asking for confirmation (default is false)")
+	dc.cmd.MarkFlagRequired("active")
+
+	return dc
+}
+
+func (c *diffCmd) diff(cmd *cobra.Command, args []string) error {
+	kPath, err := kubeconfigEnv()
+	if err != nil {
+		return err
+	}
+	sid, ok := sessionFromKubeconfig(c.sm, kPath)
+	if !ok {
+		return fmt.Errorf("KUBECONFIG %q is not managed by konf, so it cannot be compared with the store", kPath)
+	}
+
+	active, changes, err := activeDiff(c.sm, kPath, args)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		log.Info("The active konf does not differ from the store")
+		return nil
+	}
+
+	for _, ch := range changes {
+		fmt.Println(ch)
+	}
+
+	if !c.writeBack {
+		return nil
+	}
+	id, err := writeBackTarget(c.sm, sid, active, changes)
+	if err != nil {
+		return err
+	}
+
+	if !c.yes {
+		if !c.isTerminal() {
+			return fmt.Errorf("refusing to write %d change(s) back to the store without confirmation, use --yes to write them anyway", len(changes))
+		}
+		ok, err := c.confirm(fmt.Sprintf("Write %d change(s) back to konf %q", len(changes), id))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.Info("Write-back aborted")
+			return nil
+		}
+	}
+
+	if err := writeBack(c.sm, id, changes); err != nil {
+		return err
+	}
+	log.Info("Wrote %d change(s) back to konf %q", len(changes), id)
+	return nil
+}
+
+// activeDiff returns the changes between the konf in the store and the active
+// konf at kPath, which are at or below one of the supplied paths. Additionally
+// the active konf is returned
+func activeDiff(sm *store.Storemanager, kPath string, paths []string) (*k8s.Config, []konf.Change, error) {
+	b, err := afero.ReadFile(sm.Fs, kPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	active := &k8s.Config{}
+	if err := yaml.Unmarshal(b, active); err != nil {
+		return nil, nil, err
+	}
+
+	stored, err := storeKonfOf(sm, active)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	all, err := konf.Diff(stored, active)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changes := []konf.Change{}
+	for _, ch := range all {
+		if ch.Matches(paths) {
+			changes = append(changes, ch)
+		}
+	}
+	return active, changes, nil
+}
+
+// writeBackTarget returns the ID of the konf in the store the changes of the
+// active konf of session sid can be written back to. Merged konfs are not
+// supported, as their clusters, contexts and users have been renamed. Changes
+// that add, remove or rename whole clusters, contexts or users are rejected
+// as well, as the konf would not match its ID anymore. If the context or
+// cluster has been renamed to the one of another konf, the diff has been
+// created against that konf, which is caught by comparing with the konf the
+// session has been started with
+func writeBackTarget(sm *store.Storemanager, sid konf.KonfID, active *k8s.Config, changes []konf.Change) (konf.KonfID, error) {
+	ids := konf.IDsFromKubeconfig(active)
+	if len(ids) != 1 {
+		return "", fmt.Errorf("changes of merged konfs cannot be written back to the store")
+	}
+	id := ids[0]
+
+	for _, ch := range changes {
+		if ch.Structural() {
+			return "", fmt.Errorf("change of %q adds, removes or renames a cluster, context or user, so it cannot be written back to the store", ch.Path)
+		}
+	}
+
+	ses, err := sm.Session(sid)
+	if err != nil {
+		return "", err
+	}
+	if ses != nil && len(ses.Konfs) > 0 && !slices.Equal(ses.Konfs, ids) {
+		return "", fmt.Errorf("the active konf has been set as %q, but its context or cluster has been renamed to the ones of konf %q, so it cannot be written back to the store", ses.Konfs[0], id)
+	}
+
+	return id, nil
+}
+
+// writeBack applies changes of the active konf to the konf with the supplied
+// id in the store. Use writeBackTarget to determine the id. The active konf
+// itself is never modified, so a kubeconfig shared with other shells does not
+// have to be forked first
+func writeBack(sm *store.Storemanager, id konf.KonfID, changes []konf.Change) error {
+	k, err := sm.ReadKonfFromStore(id)
+	if err != nil {
+		return err
+	}
+	updated, err := konf.ApplyChanges(&k.Kubeconfig, changes)
+	if err != nil {
+		return err
+	}
+
+	b, err := yaml.Marshal(updated)
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(sm.Fs, sm.StorePathFromID(id), b, utils.KonfPerm)
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/store"
+	"github.com/simontheleg/konf-go/testhelper"
+	"github.com/simontheleg/konf-go/utils"
+	"github.com/spf13/afero"
+	k8s "k8s.io/client-go/tools/clientcmd/api/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// diffFS creates the konfs dev-eu_dev-eu-1 and dev-asia_dev-asia-1 in the
+// store and the active konf of session 100, which has been started with
+// dev-eu_dev-eu-1
+func diffFS(t *testing.T, active string) *store.Storemanager {
+	var skm testhelper.SampleKonfManager
+	sm := &store.Storemanager{Fs: afero.NewMemMapFs(), Activedir: "./konf/active", Storedir: "./konf/store", Statedir: "./konf/state"}
+	files := map[string]string{
+		sm.StorePathFromID("dev-eu_dev-eu-1"):     skm.SingleClusterSingleContextEU(),
+		sm.StorePathFromID("dev-asia_dev-asia-1"): skm.SingleClusterSingleContextASIA(),
+		sm.ActivePathFromID("100"):                active,
+	}
+	for path, content := range files {
+		if err := afero.WriteFile(sm.Fs, path, []byte(content), utils.KonfPerm); err != nil {
+			t.Fatalf("Could not create file, please check test code: %v", err)
+		}
+	}
+	if err := sm.WriteSession(&store.Session{ID: "100", Konfs: []konf.KonfID{"dev-eu_dev-eu-1"}}); err != nil {
+		t.Fatalf("Could not record session, please check test code: %v", err)
+	}
+	return sm
+}
+
+func TestActiveDiffWriteBack(t *testing.T) {
+	var skm testhelper.SampleKonfManager
+	eu := skm.SingleClusterSingleContextEU()
+	// the session has changed its namespace and server, e.g. through 'kubectl config set-context'
+	drifted := strings.ReplaceAll(strings.ReplaceAll(eu, "kube-public", "team-a"), "https://10.1.1.0", "https://10.1.1.1")
+	// the session has renamed its context and cluster to the ones of another konf
+	renamed := strings.ReplaceAll(skm.SingleClusterSingleContextASIA(), "kube-public", "team-a")
+
+	var withUser string
+	{
+		var conf k8s.Config
+		if err := yaml.Unmarshal([]byte(drifted), &conf); err != nil {
+			t.Fatalf("Could not parse konf, please check test code: %v", err)
+		}
+		conf.AuthInfos = append(conf.AuthInfos, k8s.NamedAuthInfo{Name: "admin", AuthInfo: k8s.AuthInfo{Username: "admin"}})
+		b, err := yaml.Marshal(conf)
+		if err != nil {
+			t.Fatalf("Could not marshal konf, please check test code: %v", err)
+		}
+		withUser = string(b)
+	}
+
+	tt := map[string]struct {
+		active     string
+		paths      []string
+		expChanges int
+		expErr     error
+		// expStore is the konf in the store the changes should end up in
+		expStore  konf.KonfID
+		expServer string
+		expNs     string
+	}{
+		"all changes": {
+			drifted, nil, 2, nil, "dev-eu_dev-eu-1", "https://10.1.1.1", "team-a",
+		},
+		"selected change": {
+			drifted, []string{"contexts[dev-eu]"}, 1, nil, "dev-eu_dev-eu-1", "https://10.1.1.0", "team-a",
+		},
+		"nothing selected": {
+			drifted, []string{"users"}, 0, nil, "dev-eu_dev-eu-1", "https://10.1.1.0", "kube-public",
+		},
+		"added user": {
+			withUser, nil, 3,
+			fmt.Errorf("change of %q adds, removes or renames a cluster, context or user, so it cannot be written back to the store", "users[admin]"),
+			"dev-eu_dev-eu-1", "https://10.1.1.0", "kube-public",
+		},
+		"renamed to another konf": {
+			renamed, nil, 1,
+			fmt.Errorf("the active konf has been set as %q, but its context or cluster has been renamed to the ones of konf %q, so it cannot be written back to the store", "dev-eu_dev-eu-1", "dev-asia_dev-asia-1"),
+			"dev-asia_dev-asia-1", "https://10.1.1.0", "kube-public",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			sm := diffFS(t, tc.active)
+			kPath := sm.ActivePathFromID("100")
+
+			active, changes, err := activeDiff(sm, kPath, tc.paths)
+			if err != nil {
+				t.Fatalf("Exp no error, got %v", err)
+			}
+			if len(changes) != tc.expChanges {
+				t.Fatalf("Exp %d changes, got %v", tc.expChanges, changes)
+			}
+
+			id, err := writeBackTarget(sm, "100", active, changes)
+			if !testhelper.EqualError(tc.expErr, err) {
+				t.Errorf("Exp error %q, got %q", tc.expErr, err)
+			}
+			if err == nil {
+				if err := writeBack(sm, id, changes); err != nil {
+					t.Fatalf("Exp no error, got %v", err)
+				}
+			}
+
+			k, err := sm.ReadKonfFromStore(tc.expStore)
+			if err != nil {
+				t.Fatalf("Could not read konf from store: %v", err)
+			}
+			if server := k.Kubeconfig.Clusters[0].Cluster.Server; server != tc.expServer {
+				t.Errorf("Exp server %q in store, got %q", tc.expServer, server)
+			}
+			if ns := k.Kubeconfig.Contexts[0].Context.Namespace; ns != tc.expNs {
+				t.Errorf("Exp namespace %q in store, got %q", tc.expNs, ns)
+			}
+
+			// the active konf might be shared with other shells, so it must not be touched
+			b, err := afero.ReadFile(sm.Fs, kPath)
+			if err != nil {
+				t.Fatalf("Could not read active konf: %v", err)
+			}
+			if string(b) != tc.active {
+				t.Errorf("Exp active konf to be untouched, but it changed to %q", string(b))
+			}
+		})
+	}
+}
+
+func TestDiffWriteBackConfirmation(t *testing.T) {
+	var skm testhelper.SampleKonfManager
+	drifted := strings.ReplaceAll(skm.SingleClusterSingleContextEU(), "kube-public", "team-a")
+
+	tt := map[string]struct {
+		isTerminal bool
+		answer     bool
+		yes        bool
+		expConfirm bool
+		expNs      string
+		expErr     error
+	}{
+		"confirmed":           {isTerminal: true, answer: true, expConfirm: true, expNs: "team-a"},
+		"aborted":             {isTerminal: true, answer: false, expConfirm: true, expNs: "kube-public"},
+		"yes":                 {isTerminal: true, yes: true, expNs: "team-a"},
+		"no terminal":         {expNs: "kube-public", expErr: fmt.Errorf("refusing to write 1 change(s) back to the store without confirmation, use --yes to write them anyway")},
+		"no terminal but yes": {yes: true, expNs: "team-a"},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			sm := diffFS(t, drifted)
+			t.Setenv("KUBECONFIG", sm.ActivePathFromID("100"))
+
+			confirmed := false
+			dc := &diffCmd{
+				sm:         sm,
+				confirm:    func(string) (bool, error) { confirmed = true; return tc.answer, nil },
+				isTerminal: func() bool { return tc.isTerminal },
+				active:     true,
+				writeBack:  true,
+				yes:        tc.yes,
+			}
+
+			err := dc.diff(dc.cmd, []string{})
+			if !testhelper.EqualError(tc.expErr, err) {
+				t.Errorf("Exp error %q, got %q", tc.expErr, err)
+			}
+			if confirmed != tc.expConfirm {
+				t.Errorf("Exp confirmation to be asked: %t, got %t", tc.expConfirm, confirmed)
+			}
+
+			k, err := sm.ReadKonfFromStore("dev-eu_dev-eu-1")
+			if err != nil {
+				t.Fatalf("Could not read konf from store: %v", err)
+			}
+			if ns := k.Kubeconfig.Contexts[0].Context.Namespace; ns != tc.expNs {
+				t.Errorf("Exp namespace %q in store, got %q", tc.expNs, ns)
+			}
+		})
+	}
+}
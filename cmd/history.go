@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/manifoldco/promptui"
+	"github.com/simontheleg/konf-go/config"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/prompt"
+	"github.com/simontheleg/konf-go/store"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+type historyCmd struct {
+	sm      *store.Storemanager
+	prompt  prompt.RunFunc
+	confirm prompt.ConfirmFunc
+
+	cmd *cobra.Command
+}
+
+func newHistoryCmd() *historyCmd {
+	fs := afero.NewOsFs()
+	sm := &store.Storemanager{Fs: fs, Activedir: config.ActiveDir(), Storedir: config.StoreDir(), Statedir: config.StateDir(), LatestKonfPath: config.LatestKonfFilePath()}
+	hc := &historyCmd{
+		sm:      sm,
+		prompt:  prompt.Configured(),
+		confirm: prompt.Confirm,
+	}
+
+	hc.cmd = &cobra.Command{
+		Use:   "history",
+		Short: "Select a recently used kubeconfig",
+		Long: `Run a selection of recently used konfs and set the selected one in the current shell.
+
+The most recently used konf comes first. Konfs that have since been deleted are not shown.
+To quickly go back without a selection, use 'konf set -N' instead`,
+		RunE: hc.history,
+		Args: cobra.ExactArgs(0),
+	}
+
+	return hc
+}
+
+func (c *historyCmd) history(cmd *cobra.Command, args []string) error {
+	id, err := selectRecentKonf(c.sm, c.prompt, time.Now())
+	if err != nil {
+		return err
+	}
+	// the konf has been selected in a prompt, so the user can always be asked
+	if err := confirmProtected(c.sm, id, "", c.confirm, true); err != nil {
+		return err
+	}
+
+	context, err := setContext(id, c.sm)
+	if err != nil {
+		return err
+	}
+
+	return finishSet(c.sm, id, context)
+}
+
+func selectRecentKonf(sm *store.Storemanager, pf prompt.RunFunc, now time.Time) (konf.KonfID, error) {
+	entries, err := recentKonfs(sm)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return "", fmt.Errorf("could not select latest konf, because no konf was yet set")
+	}
+
+	items := []string{}
+	for _, e := range entries {
+		items = append(items, fmt.Sprintf("%s (%s)", e.ID, prompt.TimeAgo(e.Time, now)))
+	}
+
+	// Wrapper is required as we need access to entries, but the methodSignature from promptUI
+	// requires you to only pass an index not the whole func
+	var wrapSearchHistory = func(input string, index int) bool {
+		return fuzzy.Match(input, string(entries[index].ID))
+	}
+
+	p := &promptui.Select{
+		Label:        "Select recently used konf",
+		Items:        items,
+		HideSelected: true,
+		Stdout:       os.Stderr,
+		Templates: &promptui.SelectTemplates{
+			Active: fmt.Sprintf("%s {{ . | bold | cyan }}", promptui.IconSelect),
+		},
+		Searcher: wrapSearchHistory,
+		Size:     15,
+	}
+
+	selPos, err := pf(p)
+	if err != nil {
+		return "", err
+	}
+
+	if selPos >= len(entries) {
+		return "", fmt.Errorf("invalid selection %d", selPos)
+	}
+
+	return entries[selPos].ID, nil
+}
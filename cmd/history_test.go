@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/manifoldco/promptui"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/prompt"
+	"github.com/simontheleg/konf-go/store"
+	"github.com/simontheleg/konf-go/testhelper"
+)
+
+func TestSelectRecentKonf(t *testing.T) {
+	storeDir := "./konf/store"
+	activeDir := "./konf/active"
+	stateDir := "./konf/state"
+	fm := testhelper.FilesystemManager{Storedir: storeDir, Activedir: activeDir}
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	f := testhelper.FSWithFiles(fm.StoreDir, fm.SingleClusterSingleContextEU, fm.SingleClusterSingleContextASIA)()
+	sm := &store.Storemanager{Fs: f, Activedir: activeDir, Storedir: storeDir, Statedir: stateDir}
+	// dev-eu_dev-eu-2 does not exist in the store and must therefore be skipped
+	for i, id := range []konf.KonfID{"dev-eu_dev-eu-1", "dev-asia_dev-asia-1", "dev-eu_dev-eu-2"} {
+		if err := sm.AddToHistory(id, now.Add(time.Duration(i-3)*time.Hour)); err != nil {
+			t.Fatalf("Could not create history, please check test code: %v", err)
+		}
+	}
+
+	var expItems []string
+	tt := map[string]struct {
+		pf     prompt.RunFunc
+		expID  konf.KonfID
+		expErr error
+	}{
+		"select most recent": {
+			func(s *promptui.Select) (int, error) { expItems = s.Items.([]string); return 0, nil },
+			"dev-asia_dev-asia-1",
+			nil,
+		},
+		"select second": {
+			func(s *promptui.Select) (int, error) { return 1, nil },
+			"dev-eu_dev-eu-1",
+			nil,
+		},
+		"prompt failure": {
+			func(s *promptui.Select) (int, error) { return 0, fmt.Errorf("err") },
+			"",
+			fmt.Errorf("err"),
+		},
+		"invalid selection": {
+			func(s *promptui.Select) (int, error) { return 2, nil },
+			"",
+			fmt.Errorf("invalid selection 2"),
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			res, err := selectRecentKonf(sm, tc.pf, now)
+
+			if !testhelper.EqualError(err, tc.expErr) {
+				t.Errorf("Exp err %q, got %q", tc.expErr, err)
+			}
+
+			if res != tc.expID {
+				t.Errorf("Exp id %q, got %q", tc.expID, res)
+			}
+		})
+	}
+
+	if exp := "dev-asia_dev-asia-1 (2h ago)"; len(expItems) != 2 || expItems[0] != exp {
+		t.Errorf("Exp first item to be %q, got %v", exp, expItems)
+	}
+}
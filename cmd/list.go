@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/simontheleg/konf-go/config"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/store"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+type listCmd struct {
+	sm *store.Storemanager
+
+	pinned bool
+
+	cmd *cobra.Command
+}
+
+func newListCmd() *listCmd {
+	fs := afero.NewOsFs()
+	sm := &store.Storemanager{Fs: fs, Activedir: config.ActiveDir(), Storedir: config.StoreDir(), Statedir: config.StateDir()}
+	lc := &listCmd{
+		sm: sm,
+	}
+
+	lc.cmd = &cobra.Command{
+		Use:   "list",
+		Short: "List kubeconfigs in the store",
+		Long: `Print the IDs of all konfs in the store, one per line.
+
+Examples:
+-> 'list' list all konfs in alphabetical order
+-> 'list --pinned' only list pinned konfs in the order they have been pinned
+`,
+		RunE: lc.list,
+		Args: cobra.ExactArgs(0),
+	}
+
+	lc.cmd.Flags().BoolVar(&lc.pinned, "pinned", false, "only list pinned konfs")
+
+	return lc
+}
+
+func (c *listCmd) list(cmd *cobra.Command, args []string) error {
+	ids, err := listKonfIDs(c.sm, c.pinned)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		fmt.Println(id)
+	}
+
+	return nil
+}
+
+// listKonfIDs returns the IDs of all konfs in the store. If pinnedOnly is set,
+// only the pinned konfs are returned in the order they have been pinned
+func listKonfIDs(sm *store.Storemanager, pinnedOnly bool) ([]konf.KonfID, error) {
+	konfs, err := sm.FetchAllKonfs()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []konf.KonfID{}
+	for _, k := range konfs {
+		ids = append(ids, konf.IDFromClusterAndContext(k.Cluster, k.Context))
+	}
+
+	if !pinnedOnly {
+		return ids, nil
+	}
+
+	pins, err := sm.Pins()
+	if err != nil {
+		return nil, err
+	}
+
+	pinned := []konf.KonfID{}
+	for _, id := range pins {
+		// pins of konfs that have since been deleted are skipped
+		if slices.Contains(ids, id) {
+			pinned = append(pinned, id)
+		}
+	}
+	return pinned, nil
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/store"
+	"github.com/simontheleg/konf-go/testhelper"
+)
+
+func TestListKonfIDs(t *testing.T) {
+	storeDir := "./konf/store"
+	activeDir := "./konf/active"
+	stateDir := "./konf/state"
+	fm := testhelper.FilesystemManager{Storedir: storeDir, Activedir: activeDir}
+
+	tt := map[string]struct {
+		pins       []konf.KonfID
+		pinnedOnly bool
+		expIDs     []konf.KonfID
+	}{
+		"all konfs": {
+			[]konf.KonfID{"dev-eu_dev-eu-1"},
+			false,
+			[]konf.KonfID{"dev-asia_dev-asia-1", "dev-eu_dev-eu-1"},
+		},
+		"pinned konfs in pin order": {
+			[]konf.KonfID{"dev-eu_dev-eu-1", "dev-asia_dev-asia-1"},
+			true,
+			[]konf.KonfID{"dev-eu_dev-eu-1", "dev-asia_dev-asia-1"},
+		},
+		"deleted konfs are skipped": {
+			[]konf.KonfID{"dev-us_dev-us-1", "dev-asia_dev-asia-1"},
+			true,
+			[]konf.KonfID{"dev-asia_dev-asia-1"},
+		},
+		"nothing pinned": {
+			nil,
+			true,
+			[]konf.KonfID{},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			f := testhelper.FSWithFiles(fm.StoreDir, fm.SingleClusterSingleContextEU, fm.SingleClusterSingleContextASIA)()
+			sm := &store.Storemanager{Fs: f, Activedir: activeDir, Storedir: storeDir, Statedir: stateDir}
+			for _, id := range tc.pins {
+				if _, err := sm.Pin(id); err != nil {
+					t.Fatalf("Could not pin konf, please check test code: %v", err)
+				}
+			}
+
+			ids, err := listKonfIDs(sm, tc.pinnedOnly)
+			if err != nil {
+				t.Fatalf("Exp no error, but got %v", err)
+			}
+
+			if !cmp.Equal(ids, tc.expIDs) {
+				t.Errorf("Exp ids %v, got %v", tc.expIDs, ids)
+			}
+		})
+	}
+}
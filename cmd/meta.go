@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/simontheleg/konf-go/config"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/log"
+	"github.com/simontheleg/konf-go/store"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+type metaCmd struct {
+	sm *store.Storemanager
+
+	aliases     []string
+	tags        []string
+	description string
+	ttl         ageValue
+	protected   bool
+
+	cmd *cobra.Command
+}
+
+func newMetaCmd() *metaCmd {
+	fs := afero.NewOsFs()
+	sm := &store.Storemanager{Fs: fs, Activedir: config.ActiveDir(), Storedir: config.StoreDir(), Statedir: config.StateDir()}
+	mc := &metaCmd{
+		sm: sm,
+	}
+
+	mc.cmd = &cobra.Command{
+		Use:   "meta",
+		Short: "Show or change metadata of a kubeconfig",
+		Long: `Show or change the metadata of a konf. Metadata is stored by konf itself and never
+becomes part of the kubeconfig.
+
+Aliases can be used instead of the konf ID, e.g. in 'konf set <alias>'. Aliases
+and tags are also taken into account when searching in the selection prompt.
+
+Expired konfs are revoked by 'konf cleanup'. To be warned in the shell as soon as
+a konf has expired, the shellwrapper has to be created with --check-expiry, e.g.
+'source <(konf-go shellwrapper zsh --check-expiry)'.
+
+Examples:
+-> 'meta <konfig id>' print the metadata of a konf
+-> 'meta <konfig id> --alias prod,prd' set the aliases of a konf
+-> 'meta <konfig id> --tag production --description "main cluster"' set tags and description
+-> 'meta <konfig id> --alias ""' remove all aliases of a konf
+-> 'meta <konfig id> --ttl 60m' let shells using the konf expire 60 minutes after 'konf set'
+-> 'meta <konfig id> --ttl 0' let shells using the konf never expire
+-> 'meta <konfig id> --protected' require a confirmation before switching to the konf
+-> 'meta <konfig id> --protected=false' remove the protection of a konf
+`,
+		RunE:              mc.meta,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: mc.completeMeta,
+	}
+
+	mc.cmd.Flags().StringSliceVar(&mc.aliases, "alias", nil, "aliases of the konf. Replaces all existing aliases")
+	mc.cmd.Flags().StringSliceVar(&mc.tags, "tag", nil, "tags of the konf. Replaces all existing tags")
+	mc.cmd.Flags().StringVar(&mc.description, "description", "", "description of the konf")
+	mc.cmd.Flags().BoolVar(&mc.protected, "protected", false, "protect the konf. Protected konfs are shown in red and switching to them has to be confirmed")
+	mc.cmd.Flags().Var(&mc.ttl, "ttl", "time after which the konf expires in shells it has been set in, e.g. 60m or 8h. 0 removes the ttl. Shells only warn about it if the shellwrapper has been created with --check-expiry")
+
+	return mc
+}
+
+func (c *metaCmd) meta(cmd *cobra.Command, args []string) error {
+	id := konf.KonfID(args[0])
+	if _, err := c.sm.Fs.Stat(c.sm.StorePathFromID(id)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("konf %q does not exist in the store", id)
+		}
+		return err
+	}
+
+	m, err := c.sm.Meta(id)
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	if !flags.Changed("alias") && !flags.Changed("tag") && !flags.Changed("description") && !flags.Changed("ttl") && !flags.Changed("protected") {
+		b, err := yaml.Marshal(m)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(b))
+		return nil
+	}
+
+	if flags.Changed("alias") {
+		if err := validateAliases(c.sm, id, c.aliases); err != nil {
+			return err
+		}
+		m.Aliases = c.aliases
+	}
+	if flags.Changed("tag") {
+		m.Tags = c.tags
+	}
+	if flags.Changed("description") {
+		m.Description = c.description
+	}
+	if flags.Changed("protected") {
+		m.Protected = c.protected
+	}
+	if flags.Changed("ttl") {
+		m.TTL = ""
+		if c.ttl != 0 {
+			m.TTL = c.ttl.String()
+		}
+	}
+
+	if err := c.sm.SetMeta(id, m); err != nil {
+		return err
+	}
+
+	log.Info("Updated metadata of konf %q", id)
+	// the shellwrapper does not check for expired konfs by default, so the user would not notice otherwise
+	if flags.Changed("ttl") && c.ttl != 0 {
+		log.Info("Shells only warn about an expired konf if the shellwrapper has been created with --check-expiry")
+	}
+	return nil
+}
+
+// validateAliases makes sure that aliases can be used in place of the ID of
+// the konf with the supplied id. This means they must be unique and must not
+// be confused with the ID of another konf or any of the special arguments of set
+func validateAliases(sm *store.Storemanager, id konf.KonfID, aliases []string) error {
+	for _, a := range aliases {
+		if a == "" || strings.HasPrefix(a, "-") {
+			return fmt.Errorf("invalid alias %q. Aliases must not be empty or start with a dash", a)
+		}
+		if strings.Contains(a, namespaceSeparator) {
+			return fmt.Errorf("invalid alias %q. Aliases must not contain %q, as it separates konf and namespace in 'konf set'", a, namespaceSeparator)
+		}
+
+		if _, err := sm.Fs.Stat(sm.StorePathFromID(konf.KonfID(a))); err == nil && konf.KonfID(a) != id {
+			return fmt.Errorf("invalid alias %q. It is already the ID of another konf", a)
+		}
+
+		other, found, err := sm.IDForAlias(a)
+		if err != nil {
+			return err
+		}
+		if found && other != id {
+			return fmt.Errorf("invalid alias %q. It is already used by konf %q", a, other)
+		}
+	}
+
+	return nil
+}
+
+func (c *metaCmd) completeMeta(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	konfs, err := c.sm.FetchAllKonfs()
+	if err != nil {
+		// if the store is just empty, return no suggestions, instead of throwing an error
+		if _, ok := err.(*store.EmptyStore); ok {
+			return []string{}, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	sug := []string{}
+	for _, k := range konfs {
+		sug = append(sug, string(konf.IDFromClusterAndContext(k.Cluster, k.Context)))
+	}
+
+	return sug, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/store"
+	"github.com/simontheleg/konf-go/testhelper"
+)
+
+func TestMeta(t *testing.T) {
+	storeDir := "./konf/store"
+	activeDir := "./konf/active"
+	stateDir := "./konf/state"
+	fm := testhelper.FilesystemManager{Storedir: storeDir, Activedir: activeDir}
+	eu := konf.KonfID("dev-eu_dev-eu-1")
+
+	tt := map[string]struct {
+		args    []string
+		flags   []string
+		expErr  error
+		expMeta *store.KonfMeta
+	}{
+		"set aliases, tags and description": {
+			[]string{string(eu)},
+			[]string{"--alias", "eu,dev", "--tag", "dev", "--description", "dev cluster"},
+			nil,
+			&store.KonfMeta{Aliases: []string{"eu", "dev"}, Tags: []string{"dev"}, Description: "dev cluster"},
+		},
+		"only change tags": {
+			[]string{string(eu)},
+			[]string{"--tag", "eu"},
+			nil,
+			&store.KonfMeta{Aliases: []string{"europe"}, Tags: []string{"eu"}},
+		},
+		"remove aliases": {
+			[]string{string(eu)},
+			[]string{"--alias", ""},
+			nil,
+			&store.KonfMeta{Tags: []string{"dev"}},
+		},
+		"set ttl": {
+			[]string{string(eu)},
+			[]string{"--ttl", "60m"},
+			nil,
+			&store.KonfMeta{Aliases: []string{"europe"}, Tags: []string{"dev"}, TTL: "1h0m0s"},
+		},
+		"remove ttl": {
+			[]string{string(eu)},
+			[]string{"--ttl", "0"},
+			nil,
+			&store.KonfMeta{Aliases: []string{"europe"}, Tags: []string{"dev"}},
+		},
+		"protect konf": {
+			[]string{string(eu)},
+			[]string{"--protected"},
+			nil,
+			&store.KonfMeta{Aliases: []string{"europe"}, Tags: []string{"dev"}, Protected: true},
+		},
+		"konf does not exist": {
+			[]string{"dev-us_dev-us-1"},
+			[]string{"--tag", "us"},
+			fmt.Errorf("konf %q does not exist in the store", "dev-us_dev-us-1"),
+			&store.KonfMeta{Aliases: []string{"europe"}, Tags: []string{"dev"}},
+		},
+		"alias used by other konf": {
+			[]string{string(eu)},
+			[]string{"--alias", "asia"},
+			fmt.Errorf("invalid alias %q. It is already used by konf %q", "asia", "dev-asia_dev-asia-1"),
+			&store.KonfMeta{Aliases: []string{"europe"}, Tags: []string{"dev"}},
+		},
+		"alias is ID of other konf": {
+			[]string{string(eu)},
+			[]string{"--alias", "dev-asia_dev-asia-1"},
+			fmt.Errorf("invalid alias %q. It is already the ID of another konf", "dev-asia_dev-asia-1"),
+			&store.KonfMeta{Aliases: []string{"europe"}, Tags: []string{"dev"}},
+		},
+		"alias with namespace separator": {
+			[]string{string(eu)},
+			[]string{"--alias", "eu/dev"},
+			fmt.Errorf("invalid alias %q. Aliases must not contain %q, as it separates konf and namespace in 'konf set'", "eu/dev", "/"),
+			&store.KonfMeta{Aliases: []string{"europe"}, Tags: []string{"dev"}},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			f := testhelper.FSWithFiles(fm.StoreDir, fm.SingleClusterSingleContextEU, fm.SingleClusterSingleContextASIA)()
+			sm := &store.Storemanager{Fs: f, Activedir: activeDir, Storedir: storeDir, Statedir: stateDir}
+			sm.SetMeta(eu, &store.KonfMeta{Aliases: []string{"europe"}, Tags: []string{"dev"}})
+			sm.SetMeta("dev-asia_dev-asia-1", &store.KonfMeta{Aliases: []string{"asia"}})
+
+			mc := newMetaCmd()
+			mc.sm = sm
+			if err := mc.cmd.ParseFlags(tc.flags); err != nil {
+				t.Fatalf("Could not parse flags, please check test code: %v", err)
+			}
+
+			err := mc.meta(mc.cmd, tc.args)
+			if !testhelper.EqualError(err, tc.expErr) {
+				t.Errorf("Exp err %q, got %q", tc.expErr, err)
+			}
+
+			m, _ := sm.Meta(eu)
+			if !cmp.Equal(m, tc.expMeta) {
+				t.Errorf("Exp meta %v, got %v", tc.expMeta, m)
+			}
+		})
+	}
+}
@@ -4,10 +4,15 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 
 	"github.com/lithammer/fuzzysearch/fuzzy"
 	"github.com/manifoldco/promptui"
+	"github.com/simontheleg/konf-go/config"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/log"
 	"github.com/simontheleg/konf-go/prompt"
+	"github.com/simontheleg/konf-go/store"
 	"github.com/simontheleg/konf-go/utils"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
@@ -22,11 +27,18 @@ type clientSetCreator = func(afero.Fs) (kubernetes.Interface, error)
 
 type namespaceCmd struct {
 	fs afero.Fs
+	sm *store.Storemanager
 
-	promptFunc       prompt.RunFunc
-	selectNamespace  func(clientSetCreator, prompt.RunFunc, afero.Fs) (string, error)
-	setNamespace     func(afero.Fs, string) error
-	clientSetCreator clientSetCreator
+	promptFunc          prompt.RunFunc
+	selectNamespace     func(clientSetCreator, prompt.RunFunc, afero.Fs, []string) (string, error)
+	setNamespace        func(*store.Storemanager, string) error
+	setDefaultNamespace func(*store.Storemanager, konf.KonfID, string) error
+	clientSetCreator    clientSetCreator
+	clientSetFromFile   func(afero.Fs, string) (kubernetes.Interface, error)
+
+	konfID  string
+	persist bool
+	history bool
 
 	cmd *cobra.Command
 }
@@ -34,13 +46,17 @@ type namespaceCmd struct {
 func newNamespaceCmd() *namespaceCmd {
 
 	fs := afero.NewOsFs()
+	sm := &store.Storemanager{Fs: fs, Activedir: config.ActiveDir(), Storedir: config.StoreDir(), Statedir: config.StateDir()}
 
 	cc := &namespaceCmd{
-		fs:               fs,
-		promptFunc:       prompt.Terminal,
-		selectNamespace:  selectNamespace,
-		setNamespace:     setNamespace,
-		clientSetCreator: newKubeClientSet,
+		fs:                  fs,
+		sm:                  sm,
+		promptFunc:          prompt.Configured(),
+		selectNamespace:     selectNamespace,
+		setNamespace:        setNamespace,
+		setDefaultNamespace: setDefaultNamespace,
+		clientSetCreator:    newKubeClientSet,
+		clientSetFromFile:   newKubeClientSetFromFile,
 	}
 
 	cc.cmd = &cobra.Command{
@@ -53,21 +69,65 @@ Can also be invoked via 'ns' alias
 Examples:
 -> 'ns' run namespace selection
 -> 'ns <namespace-name' set to a specific namespace
+-> 'ns -' set to the previously used namespace of the current konf
+-> 'ns --history' run namespace selection with recently used namespaces first
+-> 'ns <namespace-name> --persist' set namespace and make it the default for future 'konf set' calls
+-> 'ns --konf <konfig id> <namespace-name>' only set the default namespace of a konf in the store
 `,
 		RunE:              cc.namespace,
 		Args:              cobra.MaximumNArgs(1),
 		ValidArgsFunction: cc.completeNamespace,
 	}
 
+	cc.cmd.Flags().StringVar(&cc.konfID, "konf", "", "konf in the store whose default namespace should be set. The current shell is not modified")
+	cc.cmd.Flags().BoolVar(&cc.persist, "persist", false, "additionally set the namespace as default for the current konf in the store (default is false)")
+	cc.cmd.Flags().BoolVar(&cc.history, "history", false, "list recently used namespaces of the current konf first in the selection (default is false)")
+
 	return cc
 }
 
 func (c *namespaceCmd) namespace(cmd *cobra.Command, args []string) error {
+	if c.konfID != "" && c.persist {
+		return fmt.Errorf("flags --konf and --persist cannot be used together")
+	}
+
+	// only the store copy is modified, so other shells (including the current one) keep their namespace
+	if c.konfID != "" {
+		var ns string
+		var err error
+		if len(args) == 0 {
+			ns, err = c.selectNamespace(c.currentClientSetCreator(), c.promptFunc, c.fs, nil)
+			if err != nil {
+				return err
+			}
+		} else {
+			ns = args[0]
+		}
+		return c.setDefaultNamespace(c.sm, konf.KonfID(c.konfID), ns)
+	}
+
+	// for merged sessions, the primary konf always comes first
+	id, curNs, err := currentKonfAndNamespace(c.fs)
+	if err != nil {
+		return err
+	}
+	hist, err := c.sm.NamespaceHistory(id)
+	if err != nil {
+		return err
+	}
+
 	var ns string
-	var err error
-	// TODO think about whether a setLastNamespace func should be implemented
 	if len(args) == 0 {
-		ns, err = c.selectNamespace(c.clientSetCreator, c.promptFunc, c.fs)
+		var recent []string
+		if c.history {
+			recent = hist
+		}
+		ns, err = c.selectNamespace(c.currentClientSetCreator(), c.promptFunc, c.fs, recent)
+		if err != nil {
+			return err
+		}
+	} else if args[0] == "-" {
+		ns, err = previousNamespace(hist, curNs)
 		if err != nil {
 			return err
 		}
@@ -75,14 +135,51 @@ func (c *namespaceCmd) namespace(cmd *cobra.Command, args []string) error {
 		ns = args[0]
 	}
 
-	err = c.setNamespace(c.fs, ns)
+	err = c.setNamespace(c.sm, ns)
 	if err != nil {
 		return err
 	}
 
+	// the namespace we come from is added as well, so 'konf ns -' also works
+	// for the namespace a konf started in
+	err = c.sm.AddToNamespaceHistory(id, curNs, ns)
+	if err != nil {
+		return fmt.Errorf("could not save namespace history. As a result 'konf ns -' might not work: %q ", err)
+	}
+
+	// setNamespace has already forked an inherited kubeconfig. Persisting only modifies the store, which is not shared with any shell
+	if c.persist {
+		return c.setDefaultNamespace(c.sm, id, ns)
+	}
+
 	return nil
 }
 
+// previousNamespace returns the most recently used namespace of a history,
+// which is not the current one
+func previousNamespace(hist []string, curNs string) (string, error) {
+	for _, ns := range hist {
+		if ns != curNs {
+			return ns, nil
+		}
+	}
+	return "", fmt.Errorf("could not select previous namespace, because no other namespace was yet set for this konf")
+}
+
+// currentClientSetCreator returns the clientSetCreator to use for the
+// invocation. If a konf has been supplied via flag, its store copy is used
+// instead of $KUBECONFIG
+func (c *namespaceCmd) currentClientSetCreator() clientSetCreator {
+	if c.konfID == "" {
+		return c.clientSetCreator
+	}
+
+	path := c.sm.StorePathFromID(konf.KonfID(c.konfID))
+	return func(fs afero.Fs) (kubernetes.Interface, error) {
+		return c.clientSetFromFile(fs, path)
+	}
+}
+
 func (c *namespaceCmd) completeNamespace(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	// Note: we do not filter on our own using toComplete string as input.
 	// This is so, that shells like zsh can use their completion matching to filter the list of namespaces
@@ -91,41 +188,35 @@ func (c *namespaceCmd) completeNamespace(cmd *cobra.Command, args []string, toCo
 	// Additionally, I think this makes it easier to integrate with another fuzzy parser like fzf later on
 
 	// TODO the clientSetCreator logic for this and for selectNamespace could possibly be streamlined
-	cs, err := c.clientSetCreator(c.fs)
+	cs, err := c.currentClientSetCreator()(c.fs)
 	if err != nil {
 		cobra.CompDebugln(err.Error(), true)
 		return nil, cobra.ShellCompDirectiveError
 	}
 
-	nsl, err := cs.CoreV1().Namespaces().List(context.Background(), v1.ListOptions{})
+	nss, err := listNamespaces(cs)
 	if err != nil {
 		cobra.CompDebugln(err.Error(), true)
 		return nil, cobra.ShellCompDirectiveError
 	}
 
-	nss := []string{}
-	for _, ns := range nsl.Items {
-		nss = append(nss, ns.Name)
-	}
-
 	return nss, cobra.ShellCompDirectiveNoFileComp
 }
 
-func selectNamespace(csc clientSetCreator, pf prompt.RunFunc, fs afero.Fs) (string, error) {
+// selectNamespace runs a selection prompt of all namespaces in the cluster.
+// Namespaces in recent are listed first in the order supplied, followed by all
+// remaining namespaces
+func selectNamespace(csc clientSetCreator, pf prompt.RunFunc, fs afero.Fs, recent []string) (string, error) {
 	cs, err := csc(fs)
 	if err != nil {
 		return "", err
 	}
 
-	nsl, err := cs.CoreV1().Namespaces().List(context.Background(), v1.ListOptions{})
+	nss, err := listNamespaces(cs)
 	if err != nil {
 		return "", err
 	}
-
-	nss := []string{}
-	for _, ns := range nsl.Items {
-		nss = append(nss, ns.Name)
-	}
+	nss = orderByRecency(nss, recent)
 
 	// Wrapper is required as we need access to nss, but the methodSignature from promptUI
 	// requires you to only pass an index not the whole func
@@ -159,16 +250,55 @@ func selectNamespace(csc clientSetCreator, pf prompt.RunFunc, fs afero.Fs) (stri
 	return nss[selPos], nil
 }
 
+// orderByRecency moves all namespaces in nss that are also part of recent to
+// the front, keeping the order of recent. Namespaces in recent which are not
+// part of nss (e.g. because they have been deleted) are ignored
+func orderByRecency(nss []string, recent []string) []string {
+	out := []string{}
+	for _, r := range recent {
+		if slices.Contains(nss, r) && !slices.Contains(out, r) {
+			out = append(out, r)
+		}
+	}
+	for _, ns := range nss {
+		if !slices.Contains(out, ns) {
+			out = append(out, ns)
+		}
+	}
+	return out
+}
+
 func searchNamespace(searchTerm, curItem string) bool {
 	return fuzzy.Match(searchTerm, curItem)
 }
 
+// listNamespaces returns the names of all namespaces in the cluster
+func listNamespaces(cs kubernetes.Interface) ([]string, error) {
+	nsl, err := cs.CoreV1().Namespaces().List(context.Background(), v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	nss := []string{}
+	for _, ns := range nsl.Items {
+		nss = append(nss, ns.Name)
+	}
+
+	return nss, nil
+}
+
 func newKubeClientSet(fs afero.Fs) (kubernetes.Interface, error) {
 	kPath, err := kubeconfigEnv()
 	if err != nil {
 		return nil, err
 	}
 
+	return newKubeClientSetFromFile(fs, kPath)
+}
+
+// newKubeClientSetFromFile works like newKubeClientSet, but uses the
+// kubeconfig at kPath instead of $KUBECONFIG
+func newKubeClientSetFromFile(fs afero.Fs, kPath string) (kubernetes.Interface, error) {
 	b, err := afero.ReadFile(fs, kPath)
 	if err != nil {
 		return nil, err
@@ -192,12 +322,49 @@ func newKubeClientSet(fs afero.Fs) (kubernetes.Interface, error) {
 	return cs, nil
 }
 
-func setNamespace(fs afero.Fs, ns string) error {
+// setNamespace sets the namespace in $KUBECONFIG. If $KUBECONFIG has been
+// inherited from another shell, the namespace is set in a copy instead, so the
+// other shell keeps its namespace. See forkSession
+func setNamespace(sm *store.Storemanager, ns string) error {
 	kPath, err := kubeconfigEnv()
 	if err != nil {
 		return err
 	}
 
+	kPath, forked, err := forkSession(sm, kPath, currentSessionID())
+	if err != nil {
+		return err
+	}
+
+	err = setNamespaceInFile(sm.Fs, kPath, ns)
+	if err != nil {
+		return err
+	}
+
+	if forked {
+		log.Info("Copied the konf inherited from another shell, so the namespace is only changed in this shell")
+		changeKubeconfig(kPath)
+	}
+	return nil
+}
+
+// setDefaultNamespace sets the namespace of a konf in the store. As a result all
+// future 'konf set' calls start in this namespace. Already active konfs are
+// not modified
+func setDefaultNamespace(sm *store.Storemanager, id konf.KonfID, ns string) error {
+	err := setNamespaceInFile(sm.Fs, sm.StorePathFromID(id), ns)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Set default namespace of konf %q to %q", id, ns)
+	return nil
+}
+
+// setNamespaceInFile works like setNamespace, but modifies the kubeconfig at
+// kPath instead of $KUBECONFIG. The file is replaced atomically, so concurrent
+// readers (like other shells running 'konf set') never see a partial konf
+func setNamespaceInFile(fs afero.Fs, kPath string, ns string) error {
 	b, err := afero.ReadFile(fs, kPath)
 	if err != nil {
 		return err
@@ -209,18 +376,19 @@ func setNamespace(fs afero.Fs, ns string) error {
 		return err
 	}
 
-	if len(conf.Contexts) == 0 {
-		return fmt.Errorf("could not set namespace as contexts[] is empty in kubeconfig")
+	// for merged sessions this makes sure we only change the namespace of the primary konf
+	con, err := konf.CurrentContext(&conf)
+	if err != nil {
+		return fmt.Errorf("could not set namespace: %v", err)
 	}
-
-	conf.Contexts[0].Context.Namespace = ns // this should be safe as konf import ensures we have only one context
+	con.Context.Namespace = ns
 
 	retconf, err := yaml.Marshal(conf)
 	if err != nil {
 		return err
 	}
 
-	err = afero.WriteFile(fs, kPath, retconf, utils.KonfPerm)
+	err = utils.WriteFileAtomic(fs, kPath, retconf, utils.KonfPerm)
 	if err != nil {
 		return err
 	}
@@ -228,6 +396,44 @@ func setNamespace(fs afero.Fs, ns string) error {
 	return nil
 }
 
+// currentKonfAndNamespace returns the ID and namespace of the konf used in the
+// current shell. For merged sessions the primary konf is used
+func currentKonfAndNamespace(fs afero.Fs) (konf.KonfID, string, error) {
+	conf, _, err := activeKubeconfig(fs)
+	if err != nil {
+		return "", "", err
+	}
+
+	con, err := konf.CurrentContext(conf)
+	if err != nil {
+		return "", "", err
+	}
+
+	return konf.IDsFromKubeconfig(conf)[0], con.Context.Namespace, nil
+}
+
+// activeKubeconfig reads the kubeconfig $KUBECONFIG points to. Additionally
+// the path of the kubeconfig is returned
+func activeKubeconfig(fs afero.Fs) (*k8s.Config, string, error) {
+	kPath, err := kubeconfigEnv()
+	if err != nil {
+		return nil, "", err
+	}
+
+	b, err := afero.ReadFile(fs, kPath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	conf := &k8s.Config{}
+	err = yaml.Unmarshal(b, conf)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return conf, kPath, nil
+}
+
 func kubeconfigEnv() (string, error) {
 	kPath := os.Getenv("KUBECONFIG")
 	if kPath == "" {
@@ -6,8 +6,11 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/manifoldco/promptui"
+	"github.com/simontheleg/konf-go/konf"
 	"github.com/simontheleg/konf-go/prompt"
+	"github.com/simontheleg/konf-go/store"
 	"github.com/simontheleg/konf-go/testhelper"
+	"github.com/simontheleg/konf-go/utils"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -19,43 +22,107 @@ import (
 
 func TestNamespace(t *testing.T) {
 
+	storeDir := "./konf/store"
+	activeDir := "./konf/active"
+	fm := testhelper.FilesystemManager{Storedir: storeDir, Activedir: activeDir}
+
 	selectNamespaceCalled := false
 	setNamespaceCalled := false
-	var mockSelectNamespace = func(clientSetCreator, prompt.RunFunc, afero.Fs) (string, error) {
+	var setDefaultNamespaceID konf.KonfID
+	var mockSelectNamespace = func(clientSetCreator, prompt.RunFunc, afero.Fs, []string) (string, error) {
 		selectNamespaceCalled = true
 		return "", nil
 	}
-	var mockSetNamespace = func(afero.Fs, string) error { setNamespaceCalled = true; return nil }
+	var setNamespaceNs string
+	var mockSetNamespace = func(sm *store.Storemanager, ns string) error {
+		setNamespaceCalled = true
+		setNamespaceNs = ns
+		return nil
+	}
+	var mockSetDefaultNamespace = func(sm *store.Storemanager, id konf.KonfID, ns string) error {
+		setDefaultNamespaceID = id
+		return nil
+	}
 
 	nscmd := newNamespaceCmd()
 	nscmd.selectNamespace = mockSelectNamespace
 	nscmd.setNamespace = mockSetNamespace
+	nscmd.setDefaultNamespace = mockSetDefaultNamespace
+	t.Setenv("KUBECONFIG", activeDir+"/dev-eu_dev-eu-1.yaml")
 
 	type ExpCalls struct {
 		SelectNamespace bool
 		SetNamespace    bool
+		// namespace that setNamespace was called with. Only checked if not empty
+		SetNamespaceNs string
+		// ID that setDefaultNamespace was called with. Empty if it should not have been called
+		SetDefaultNamespaceID konf.KonfID
 	}
 	tt := map[string]struct {
-		Args   []string
-		ExpErr error
+		Args    []string
+		KonfID  string
+		Persist bool
+		ExpErr  error
 		ExpCalls
 	}{
 		"1 arg": {
 			[]string{"ns1"},
+			"",
+			false,
 			nil,
 			ExpCalls{SelectNamespace: false, SetNamespace: true},
 		},
 		"0 args": {
 			[]string{},
+			"",
+			false,
 			nil,
 			ExpCalls{SelectNamespace: true, SetNamespace: true},
 		},
+		"previous namespace": {
+			[]string{"-"},
+			"",
+			false,
+			nil,
+			ExpCalls{SelectNamespace: false, SetNamespace: true, SetNamespaceNs: "kube-system"},
+		},
+		"konf flag": {
+			[]string{"ns1"},
+			"dev-asia_dev-asia-1",
+			false,
+			nil,
+			ExpCalls{SelectNamespace: false, SetNamespace: false, SetDefaultNamespaceID: "dev-asia_dev-asia-1"},
+		},
+		"persist flag": {
+			[]string{"ns1"},
+			"",
+			true,
+			nil,
+			ExpCalls{SelectNamespace: false, SetNamespace: true, SetDefaultNamespaceID: "dev-eu_dev-eu-1"},
+		},
+		"konf and persist flag": {
+			[]string{"ns1"},
+			"dev-asia_dev-asia-1",
+			true,
+			fmt.Errorf("flags --konf and --persist cannot be used together"),
+			ExpCalls{SelectNamespace: false, SetNamespace: false},
+		},
 	}
 
 	for name, tc := range tt {
 		t.Run(name, func(t *testing.T) {
 			selectNamespaceCalled = false
 			setNamespaceCalled = false
+			setDefaultNamespaceID = ""
+			setNamespaceNs = ""
+			nscmd.fs = testhelper.FSWithFiles(fm.ActiveDir, fm.SingleClusterSingleContextEU)()
+			nscmd.sm = &store.Storemanager{Fs: nscmd.fs, Activedir: activeDir, Storedir: storeDir, Statedir: "./konf/state"}
+			// the active konf starts in kube-public, so this is the namespace to go back to
+			if err := nscmd.sm.AddToNamespaceHistory("dev-eu_dev-eu-1", "kube-system", "kube-public"); err != nil {
+				t.Fatalf("Could not create namespace history, please check test code: %v", err)
+			}
+			nscmd.konfID = tc.KonfID
+			nscmd.persist = tc.Persist
 			cmd := nscmd.cmd
 
 			err := cmd.RunE(cmd, tc.Args)
@@ -71,6 +138,14 @@ func TestNamespace(t *testing.T) {
 				t.Errorf("Exp SetNamespaceCalled to be %t, but got %t", tc.ExpCalls.SetNamespace, setNamespaceCalled)
 			}
 
+			if tc.ExpCalls.SetNamespaceNs != "" && tc.ExpCalls.SetNamespaceNs != setNamespaceNs {
+				t.Errorf("Exp SetNamespace to be called with %q, but got %q", tc.ExpCalls.SetNamespaceNs, setNamespaceNs)
+			}
+
+			if tc.ExpCalls.SetDefaultNamespaceID != setDefaultNamespaceID {
+				t.Errorf("Exp SetDefaultNamespace to be called with %q, but got %q", tc.ExpCalls.SetDefaultNamespaceID, setDefaultNamespaceID)
+			}
+
 		})
 	}
 }
@@ -251,7 +326,7 @@ func TestSelectNamespace(t *testing.T) {
 
 	for name, tc := range tt {
 		t.Run(name, func(t *testing.T) {
-			res, err := selectNamespace(tc.csc, tc.sel, nil)
+			res, err := selectNamespace(tc.csc, tc.sel, nil, nil)
 
 			if !testhelper.EqualError(err, tc.expErr) {
 				t.Errorf("Exp err %q, got %q", tc.expErr, err)
@@ -269,35 +344,62 @@ func TestSetNamespace(t *testing.T) {
 	storeDir := "./konf/store"
 	activeDir := "./konf/active"
 	fm := testhelper.FilesystemManager{Storedir: storeDir, Activedir: activeDir}
+	var skm testhelper.SampleKonfManager
+	writeKonf := func(path string) func(afero.Fs) {
+		return func(fs afero.Fs) {
+			afero.WriteFile(fs, path, []byte(skm.SingleClusterSingleContextEU()), utils.KonfPerm)
+		}
+	}
+	t.Setenv(sessionEnv, "shell_1")
 
 	tt := map[string]struct {
 		kubeenv   string
 		FSCreator func() afero.Fs
 		ns        string
-		ExpErr    bool
+		// path the namespace should have been set in
+		expPath string
+		ExpErr  bool
 	}{
 		"no $KUBECONFIG set": {
 			"",
 			testhelper.FSWithFiles(),
 			"",
+			"",
 			true,
 		},
-		"valid kubeconfig": {
+		"kubeconfig of own session": {
+			"./konf/active/shell_1.yaml",
+			testhelper.FSWithFiles(fm.ActiveDir, writeKonf("./konf/active/shell_1.yaml")),
+			"kube-system",
+			"./konf/active/shell_1.yaml",
+			false,
+		},
+		"kubeconfig inherited from another session": {
 			"./konf/active/dev-eu_dev-eu-1.yaml",
 			testhelper.FSWithFiles(fm.ActiveDir, fm.SingleClusterSingleContextEU),
 			"kube-system",
+			"./konf/active/shell_1.yaml",
+			false,
+		},
+		"kubeconfig not managed by konf": {
+			"./kube/config",
+			testhelper.FSWithFiles(writeKonf("./kube/config")),
+			"kube-system",
+			"./kube/config",
 			false,
 		},
 		"invalid kubeconfig": {
 			"./konf/active/no-konf.yaml",
 			testhelper.FSWithFiles(fm.ActiveDir, fm.InvalidYaml),
 			"kube-system",
+			"",
 			true,
 		},
 		"valid kubeconfig, but missing context[]": {
 			"./konf/active/no-context.yaml",
 			testhelper.FSWithFiles(fm.ActiveDir, fm.KonfWithoutContext),
 			"kube-system",
+			"",
 			true,
 		},
 	}
@@ -307,15 +409,17 @@ func TestSetNamespace(t *testing.T) {
 			t.Setenv("KUBECONFIG", tc.kubeenv)
 
 			fs := tc.FSCreator()
+			sm := &store.Storemanager{Fs: fs, Activedir: activeDir, Storedir: storeDir, Statedir: "./konf/state"}
+			orig, _ := afero.ReadFile(fs, tc.kubeenv)
 
-			err := setNamespace(fs, tc.ns)
+			err := setNamespace(sm, tc.ns)
 
 			if err != nil && tc.ExpErr == false {
 				t.Errorf("Exp no error, but got: %v", err)
 			}
 
 			if tc.ExpErr == false {
-				b, err := afero.ReadFile(fs, tc.kubeenv)
+				b, err := afero.ReadFile(fs, tc.expPath)
 				if err != nil {
 					t.Errorf("failed to read file %q", err)
 				}
@@ -330,6 +434,143 @@ func TestSetNamespace(t *testing.T) {
 				if resNs != tc.ns {
 					t.Errorf("exp ns to be %q, but is %q", tc.ns, resNs)
 				}
+
+				// the shell the kubeconfig has been inherited from must keep its namespace
+				if tc.expPath != tc.kubeenv {
+					b, _ := afero.ReadFile(fs, tc.kubeenv)
+					if diff := cmp.Diff(string(orig), string(b)); diff != "" {
+						t.Errorf("Exp inherited kubeconfig to be unchanged, but got diff (-want +got):\n%s", diff)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSetDefaultNamespace(t *testing.T) {
+	storeDir := "./konf/store"
+	activeDir := "./konf/active"
+	fm := testhelper.FilesystemManager{Storedir: storeDir, Activedir: activeDir}
+
+	tt := map[string]struct {
+		id     konf.KonfID
+		ExpErr bool
+	}{
+		"konf exists": {
+			"dev-eu_dev-eu-1",
+			false,
+		},
+		"konf does not exist": {
+			"i-do-not-exist",
+			true,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			f := testhelper.FSWithFiles(fm.StoreDir, fm.ActiveDir, fm.SingleClusterSingleContextEU)()
+			sm := &store.Storemanager{Fs: f, Storedir: storeDir, Activedir: activeDir}
+
+			err := setDefaultNamespace(sm, tc.id, "kube-system")
+			if (err != nil) != tc.ExpErr {
+				t.Fatalf("Exp error to be %t, got %v", tc.ExpErr, err)
+			}
+
+			if tc.ExpErr {
+				return
+			}
+
+			k, err := sm.ReadKonfFromStore(tc.id)
+			if err != nil {
+				t.Fatalf("Could not read konf from store: %v", err)
+			}
+			if ns := k.Kubeconfig.Contexts[0].Context.Namespace; ns != "kube-system" {
+				t.Errorf("Exp store namespace to be %q, but is %q", "kube-system", ns)
+			}
+
+			// active konfs of other shells must not be touched
+			b, err := afero.ReadFile(f, sm.ActivePathFromID(tc.id))
+			if err != nil {
+				t.Fatalf("Could not read active konf: %v", err)
+			}
+			skm := testhelper.SampleKonfManager{}
+			if string(b) != skm.SingleClusterSingleContextEU() {
+				t.Errorf("Exp active konf to be untouched, but it changed to %q", string(b))
+			}
+		})
+	}
+}
+
+func TestPreviousNamespace(t *testing.T) {
+	tt := map[string]struct {
+		hist   []string
+		curNs  string
+		expNs  string
+		expErr error
+	}{
+		"current namespace is most recent": {
+			[]string{"kube-system", "kube-public", "default"},
+			"kube-system",
+			"kube-public",
+			nil,
+		},
+		"current namespace is not part of history": {
+			[]string{"kube-system", "kube-public"},
+			"default",
+			"kube-system",
+			nil,
+		},
+		"no other namespace": {
+			[]string{"kube-system"},
+			"kube-system",
+			"",
+			fmt.Errorf("could not select previous namespace, because no other namespace was yet set for this konf"),
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			res, err := previousNamespace(tc.hist, tc.curNs)
+
+			if !testhelper.EqualError(err, tc.expErr) {
+				t.Errorf("Exp err %q, got %q", tc.expErr, err)
+			}
+
+			if res != tc.expNs {
+				t.Errorf("Exp namespace to be %q, got %q", tc.expNs, res)
+			}
+		})
+	}
+}
+
+func TestOrderByRecency(t *testing.T) {
+	tt := map[string]struct {
+		nss    []string
+		recent []string
+		exp    []string
+	}{
+		"no recent namespaces": {
+			[]string{"default", "kube-public", "kube-system"},
+			nil,
+			[]string{"default", "kube-public", "kube-system"},
+		},
+		"recent namespaces first": {
+			[]string{"default", "kube-public", "kube-system"},
+			[]string{"kube-system", "default"},
+			[]string{"kube-system", "default", "kube-public"},
+		},
+		"recent namespace was deleted": {
+			[]string{"default", "kube-public"},
+			[]string{"deleted", "kube-public"},
+			[]string{"kube-public", "default"},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			res := orderByRecency(tc.nss, tc.recent)
+			if !cmp.Equal(res, tc.exp) {
+				t.Errorf("Exp namespaces %v, got %v", tc.exp, res)
 			}
 		})
 	}
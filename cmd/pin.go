@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/simontheleg/konf-go/config"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/log"
+	"github.com/simontheleg/konf-go/store"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+type pinCmd struct {
+	sm *store.Storemanager
+
+	cmd *cobra.Command
+}
+
+func newPinCmd() *pinCmd {
+	fs := afero.NewOsFs()
+	sm := &store.Storemanager{Fs: fs, Activedir: config.ActiveDir(), Storedir: config.StoreDir(), Statedir: config.StateDir()}
+	pc := &pinCmd{
+		sm: sm,
+	}
+
+	pc.cmd = &cobra.Command{
+		Use:   "pin",
+		Short: "Pin kubeconfig to the top of the selection",
+		Long: `Pin one or multiple konfs, so they are always listed at the top of the selection prompt.
+
+Pinned konfs are listed in the order they have been pinned.
+
+Examples:
+-> 'pin <konfig id> [<konfig id 2>]' pin specific konf(s)
+`,
+		RunE:              pc.pin,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: pc.completePin,
+	}
+
+	return pc
+}
+
+func (c *pinCmd) pin(cmd *cobra.Command, args []string) error {
+	for _, a := range args {
+		id := konf.KonfID(a)
+		if _, err := c.sm.Fs.Stat(c.sm.StorePathFromID(id)); err != nil {
+			return fmt.Errorf("could not pin konf %q: %v", id, err)
+		}
+
+		pinned, err := c.sm.Pin(id)
+		if err != nil {
+			return err
+		}
+		if !pinned {
+			log.Info("Konf %q is already pinned", id)
+			continue
+		}
+		log.Info("Pinned konf %q", id)
+	}
+
+	return nil
+}
+
+func (c *pinCmd) completePin(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	konfs, err := c.sm.FetchAllKonfs()
+	if err != nil {
+		// if the store is just empty, return no suggestions, instead of throwing an error
+		if _, ok := err.(*store.EmptyStore); ok {
+			return []string{}, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	pins, err := c.sm.Pins()
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	sug := []string{}
+	for _, k := range konfs {
+		id := konf.IDFromClusterAndContext(k.Cluster, k.Context)
+		// there is no point in suggesting konfs that are already pinned
+		if !slices.Contains(pins, id) && !slices.Contains(args, string(id)) {
+			sug = append(sug, string(id))
+		}
+	}
+
+	return sug, cobra.ShellCompDirectiveNoFileComp
+}
+
+type unpinCmd struct {
+	sm *store.Storemanager
+
+	cmd *cobra.Command
+}
+
+func newUnpinCmd() *unpinCmd {
+	fs := afero.NewOsFs()
+	sm := &store.Storemanager{Fs: fs, Activedir: config.ActiveDir(), Storedir: config.StoreDir(), Statedir: config.StateDir()}
+	uc := &unpinCmd{
+		sm: sm,
+	}
+
+	uc.cmd = &cobra.Command{
+		Use:   "unpin",
+		Short: "Unpin kubeconfig",
+		Long: `Unpin one or multiple konfs, so they are sorted like any other konf in the selection prompt again.
+
+Examples:
+-> 'unpin <konfig id> [<konfig id 2>]' unpin specific konf(s)
+`,
+		RunE:              uc.unpin,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: uc.completeUnpin,
+	}
+
+	return uc
+}
+
+func (c *unpinCmd) unpin(cmd *cobra.Command, args []string) error {
+	for _, a := range args {
+		id := konf.KonfID(a)
+		// no need to check the store here, as it must be possible to unpin konfs that have since been deleted
+		unpinned, err := c.sm.Unpin(id)
+		if err != nil {
+			return err
+		}
+		if !unpinned {
+			log.Info("Konf %q is not pinned", id)
+			continue
+		}
+		log.Info("Unpinned konf %q", id)
+	}
+
+	return nil
+}
+
+func (c *unpinCmd) completeUnpin(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	pins, err := c.sm.Pins()
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	sug := []string{}
+	for _, id := range pins {
+		if !slices.Contains(args, string(id)) {
+			sug = append(sug, string(id))
+		}
+	}
+
+	return sug, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/store"
+	"github.com/simontheleg/konf-go/testhelper"
+)
+
+func TestPin(t *testing.T) {
+	storeDir := "./konf/store"
+	activeDir := "./konf/active"
+	stateDir := "./konf/state"
+	fm := testhelper.FilesystemManager{Storedir: storeDir, Activedir: activeDir}
+
+	tt := map[string]struct {
+		args    []string
+		expErr  bool
+		expPins []konf.KonfID
+	}{
+		"pin multiple konfs": {
+			[]string{"dev-eu_dev-eu-1", "dev-asia_dev-asia-1"},
+			false,
+			[]konf.KonfID{"dev-eu_dev-eu-1", "dev-asia_dev-asia-1"},
+		},
+		"pin konf twice": {
+			[]string{"dev-eu_dev-eu-1", "dev-eu_dev-eu-1"},
+			false,
+			[]konf.KonfID{"dev-eu_dev-eu-1"},
+		},
+		"konf does not exist": {
+			[]string{"dev-us_dev-us-1"},
+			true,
+			[]konf.KonfID{},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			f := testhelper.FSWithFiles(fm.StoreDir, fm.SingleClusterSingleContextEU, fm.SingleClusterSingleContextASIA)()
+			sm := &store.Storemanager{Fs: f, Activedir: activeDir, Storedir: storeDir, Statedir: stateDir}
+			pc := newPinCmd()
+			pc.sm = sm
+
+			err := pc.pin(pc.cmd, tc.args)
+			if (err != nil) != tc.expErr {
+				t.Errorf("Exp error to be %t, got %v", tc.expErr, err)
+			}
+
+			pins, _ := sm.Pins()
+			if !cmp.Equal(pins, tc.expPins) {
+				t.Errorf("Exp pins %v, got %v", tc.expPins, pins)
+			}
+		})
+	}
+}
+
+func TestUnpin(t *testing.T) {
+	stateDir := "./konf/state"
+
+	tt := map[string]struct {
+		args    []string
+		expErr  error
+		expPins []konf.KonfID
+	}{
+		"unpin konf": {
+			[]string{"dev-eu_dev-eu-1"},
+			nil,
+			[]konf.KonfID{"dev-asia_dev-asia-1", "dev-us_dev-us-1"},
+		},
+		// it must be possible to unpin konfs that are no longer in the store
+		"unpin deleted konf": {
+			[]string{"dev-us_dev-us-1"},
+			nil,
+			[]konf.KonfID{"dev-eu_dev-eu-1", "dev-asia_dev-asia-1"},
+		},
+		"konf is not pinned": {
+			[]string{"dev-eu_dev-eu-2"},
+			nil,
+			[]konf.KonfID{"dev-eu_dev-eu-1", "dev-asia_dev-asia-1", "dev-us_dev-us-1"},
+		},
+		// like pin, unpin continues with the remaining konfs
+		"first konf is not pinned": {
+			[]string{"dev-eu_dev-eu-2", "dev-eu_dev-eu-1"},
+			nil,
+			[]konf.KonfID{"dev-asia_dev-asia-1", "dev-us_dev-us-1"},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			f := testhelper.FSWithFiles()()
+			sm := &store.Storemanager{Fs: f, Statedir: stateDir}
+			for _, id := range []konf.KonfID{"dev-eu_dev-eu-1", "dev-asia_dev-asia-1", "dev-us_dev-us-1"} {
+				sm.Pin(id)
+			}
+			uc := newUnpinCmd()
+			uc.sm = sm
+
+			err := uc.unpin(uc.cmd, tc.args)
+			if !testhelper.EqualError(err, tc.expErr) {
+				t.Errorf("Exp err %q, got %q", tc.expErr, err)
+			}
+
+			pins, _ := sm.Pins()
+			if !cmp.Equal(pins, tc.expPins) {
+				t.Errorf("Exp pins %v, got %v", tc.expPins, pins)
+			}
+		})
+	}
+}
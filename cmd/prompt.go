@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/simontheleg/konf-go/config"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/prompt"
+	"github.com/simontheleg/konf-go/store"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+// defaultPromptFormat is the format used by 'konf prompt' if no format has
+// been supplied
+const defaultPromptFormat = "{{ .Konf }}:{{ .Namespace }}"
+
+// promptInfo describes the konf used in the current shell. It is the data
+// passed to the format of 'konf prompt'
+type promptInfo struct {
+	// Konf is the ID of the primary konf
+	Konf konf.KonfID
+	// Konfs are the IDs of all konfs in the current shell
+	Konfs     []konf.KonfID
+	Namespace string
+	// Protected is true if any konf in the current shell is protected
+	Protected bool
+	// Expires is the time left until the konf of the current shell expires. It
+	// is empty if it never expires
+	Expires string
+}
+
+type promptCmd struct {
+	fs afero.Fs
+	sm *store.Storemanager
+
+	format string
+
+	cmd *cobra.Command
+}
+
+func newPromptCmd() *promptCmd {
+	fs := afero.NewOsFs()
+	sm := &store.Storemanager{Fs: fs, Activedir: config.ActiveDir(), Storedir: config.StoreDir(), Statedir: config.StateDir()}
+	pc := &promptCmd{
+		fs: fs,
+		sm: sm,
+	}
+
+	pc.cmd = &cobra.Command{
+		Use:   "prompt",
+		Short: "Print the konf of the current shell for use in a shell prompt",
+		Long: `Print the konf and namespace used in the current shell, so they can be shown in a shell prompt.
+If no konf is used in the current shell, nothing is printed.
+
+The output can be customized with a Go template. Available fields are .Konf, .Konfs, .Namespace,
+.Protected and .Expires
+
+Examples:
+-> 'prompt' print '<konfig id>:<namespace>'
+-> 'prompt --format "{{ if .Protected }}PROD {{ end }}{{ .Konf }}"' mark protected konfs
+`,
+		RunE: pc.prompt,
+		Args: cobra.NoArgs,
+	}
+
+	pc.cmd.Flags().StringVar(&pc.format, "format", defaultPromptFormat, "Go template used to print the konf")
+
+	return pc
+}
+
+func (c *promptCmd) prompt(cmd *cobra.Command, args []string) error {
+	tmpl, err := template.New("prompt").Parse(c.format)
+	if err != nil {
+		return fmt.Errorf("invalid format %q: %v", c.format, err)
+	}
+
+	info, err := currentPromptInfo(c.fs, c.sm, time.Now())
+	// a shell prompt should never show an error just because no konf has been set
+	if err != nil || info == nil {
+		return nil
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, info); err != nil {
+		return err
+	}
+	fmt.Println(b.String())
+	return nil
+}
+
+// currentPromptInfo collects everything a shell prompt might want to show
+// about the konf used in the current shell. If no konf is used, nil is returned
+func currentPromptInfo(fs afero.Fs, sm *store.Storemanager, now time.Time) (*promptInfo, error) {
+	if os.Getenv("KUBECONFIG") == "" {
+		return nil, nil
+	}
+
+	ids, err := currentKonfIDs(fs)
+	if err != nil {
+		return nil, err
+	}
+	_, ns, err := currentKonfAndNamespace(fs)
+	if err != nil {
+		return nil, err
+	}
+	protected, err := anyProtected(sm, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &promptInfo{Konf: ids[0], Konfs: ids, Namespace: orDefault(ns, "default"), Protected: protected}
+
+	kPath, _ := kubeconfigEnv()
+	ses, err := sessionOfKubeconfig(sm, kPath)
+	if err != nil {
+		return nil, err
+	}
+	if ses != nil && !ses.Expires.IsZero() {
+		info.Expires = prompt.TimeLeft(ses.Expires, now)
+	}
+
+	return info, nil
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/store"
+	"github.com/simontheleg/konf-go/testhelper"
+)
+
+func TestCurrentPromptInfo(t *testing.T) {
+	storeDir := "./konf/store"
+	activeDir := "./konf/active"
+	fm := testhelper.FilesystemManager{Storedir: storeDir, Activedir: activeDir}
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	eu := konf.KonfID("dev-eu_dev-eu-1")
+
+	tt := map[string]struct {
+		kubeenv   string
+		protected bool
+		expires   time.Time
+		exp       *promptInfo
+	}{
+		"no konf set": {
+			"",
+			false,
+			time.Time{},
+			nil,
+		},
+		"konf": {
+			"./konf/active/dev-eu_dev-eu-1.yaml",
+			false,
+			time.Time{},
+			&promptInfo{Konf: eu, Konfs: []konf.KonfID{eu}, Namespace: "kube-public"},
+		},
+		"protected konf that expires": {
+			"./konf/active/dev-eu_dev-eu-1.yaml",
+			true,
+			now.Add(42 * time.Minute),
+			&promptInfo{Konf: eu, Konfs: []konf.KonfID{eu}, Namespace: "kube-public", Protected: true, Expires: "42m"},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("KUBECONFIG", tc.kubeenv)
+			fs := testhelper.FSWithFiles(fm.StoreDir, fm.ActiveDir, fm.SingleClusterSingleContextEU)()
+			sm := &store.Storemanager{Fs: fs, Activedir: activeDir, Storedir: storeDir, Statedir: "./konf/state"}
+			sm.SetMeta(eu, &store.KonfMeta{Protected: tc.protected})
+			sm.WriteSession(&store.Session{ID: eu, Expires: tc.expires})
+
+			info, err := currentPromptInfo(fs, sm, now)
+			if err != nil {
+				t.Fatalf("Exp no error, got %v", err)
+			}
+			if diff := cmp.Diff(tc.exp, info); diff != "" {
+				t.Errorf("Exp prompt info to match (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
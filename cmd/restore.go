@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/manifoldco/promptui"
+	"github.com/simontheleg/konf-go/config"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/log"
+	"github.com/simontheleg/konf-go/prompt"
+	"github.com/simontheleg/konf-go/store"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+type restoreCmd struct {
+	sm     *store.Storemanager
+	prompt prompt.RunFunc
+
+	cmd *cobra.Command
+}
+
+func newRestoreCmd() *restoreCmd {
+	fs := afero.NewOsFs()
+	sm := &store.Storemanager{Fs: fs, Activedir: config.ActiveDir(), Storedir: config.StoreDir(), Statedir: config.StateDir(), Trashdir: config.TrashDir()}
+	rc := &restoreCmd{
+		sm:     sm,
+		prompt: prompt.Configured(),
+	}
+
+	rc.cmd = &cobra.Command{
+		Use:   "restore",
+		Short: "Restore a deleted kubeconfig",
+		Long: `Restore a konf from the trash, together with its aliases, tags and pin.
+
+If the same konf has been deleted multiple times, the most recently deleted one is restored.
+
+Examples:
+-> 'restore' run selection prompt for the konfs in the trash
+-> 'restore <konfig id>' restore a specific konf
+`,
+		RunE:              rc.restore,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: rc.completeRestore,
+	}
+
+	return rc
+}
+
+func (c *restoreCmd) restore(cmd *cobra.Command, args []string) error {
+	trashed, err := c.sm.TrashedKonfs()
+	if err != nil {
+		return err
+	}
+	if len(trashed) == 0 {
+		return fmt.Errorf("there is nothing to restore, the trash is empty")
+	}
+
+	var t *store.TrashedKonf
+	if len(args) == 0 {
+		t, err = selectTrashedKonf(trashed, c.prompt, time.Now())
+		if err != nil {
+			return err
+		}
+	} else {
+		t = findTrashedKonf(trashed, konf.KonfID(args[0]))
+		if t == nil {
+			return fmt.Errorf("konf %q is not in the trash", args[0])
+		}
+	}
+
+	dropped, err := c.sm.Restore(t)
+	if err != nil {
+		return err
+	}
+	if len(dropped) > 0 {
+		log.Warn("Aliases %s are used by other konfs by now and have not been restored", strings.Join(dropped, ", "))
+	}
+
+	log.Info("Restored konf %q", t.ID)
+	return nil
+}
+
+// findTrashedKonf returns the most recently deleted konf with the supplied id
+// from trashed, or nil if there is none
+func findTrashedKonf(trashed []*store.TrashedKonf, id konf.KonfID) *store.TrashedKonf {
+	// trashed is sorted with the most recently deleted konf first
+	for _, t := range trashed {
+		if t.ID == id {
+			return t
+		}
+	}
+	return nil
+}
+
+func selectTrashedKonf(trashed []*store.TrashedKonf, pf prompt.RunFunc, now time.Time) (*store.TrashedKonf, error) {
+	items := []string{}
+	for _, t := range trashed {
+		items = append(items, fmt.Sprintf("%s (deleted %s)", t.ID, prompt.TimeAgo(t.DeletedAt, now)))
+	}
+
+	// Wrapper is required as we need access to trashed, but the methodSignature from promptUI
+	// requires you to only pass an index not the whole func
+	var wrapSearchTrash = func(input string, index int) bool {
+		return fuzzy.Match(input, string(trashed[index].ID))
+	}
+
+	p := &promptui.Select{
+		Label:        "Select konf to restore",
+		Items:        items,
+		HideSelected: true,
+		Stdout:       os.Stderr,
+		Templates: &promptui.SelectTemplates{
+			Active: fmt.Sprintf("%s {{ . | bold | cyan }}", promptui.IconSelect),
+		},
+		Searcher: wrapSearchTrash,
+		Size:     15,
+	}
+
+	selPos, err := pf(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if selPos >= len(trashed) {
+		return nil, fmt.Errorf("invalid selection %d", selPos)
+	}
+
+	return trashed[selPos], nil
+}
+
+func (c *restoreCmd) completeRestore(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return []string{}, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	trashed, err := c.sm.TrashedKonfs()
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	sug := []string{}
+	for _, t := range trashed {
+		// the same konf can be in the trash multiple times
+		if !slices.Contains(sug, string(t.ID)) {
+			sug = append(sug, string(t.ID))
+		}
+	}
+
+	return sug, cobra.ShellCompDirectiveNoFileComp
+}
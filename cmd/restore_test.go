@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/manifoldco/promptui"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/prompt"
+	"github.com/simontheleg/konf-go/store"
+	"github.com/simontheleg/konf-go/testhelper"
+	"github.com/spf13/afero"
+)
+
+func TestRestore(t *testing.T) {
+	storeDir := "./konf/store"
+	activeDir := "./konf/active"
+	stateDir := "./konf/state"
+	trashDir := "./konf/trash"
+	fm := testhelper.FilesystemManager{Storedir: storeDir, Activedir: activeDir}
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tt := map[string]struct {
+		args       []string
+		pf         prompt.RunFunc
+		expErr     error
+		expRestore konf.KonfID
+	}{
+		"restore specific konf": {
+			[]string{"dev-asia_dev-asia-1"},
+			nil,
+			nil,
+			"dev-asia_dev-asia-1",
+		},
+		"restore selected konf": {
+			[]string{},
+			func(s *promptui.Select) (int, error) { return 1, nil },
+			nil,
+			"dev-asia_dev-asia-1",
+		},
+		"konf is not in the trash": {
+			[]string{"dev-us_dev-us-1"},
+			nil,
+			fmt.Errorf("konf %q is not in the trash", "dev-us_dev-us-1"),
+			"",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			f := testhelper.FSWithFiles(fm.StoreDir, fm.SingleClusterSingleContextEU, fm.SingleClusterSingleContextASIA)()
+			sm := &store.Storemanager{Fs: f, Activedir: activeDir, Storedir: storeDir, Statedir: stateDir, Trashdir: trashDir}
+			if _, err := sm.Trash("dev-asia_dev-asia-1", now); err != nil {
+				t.Fatalf("Could not create trash, please check test code: %v", err)
+			}
+			if _, err := sm.Trash("dev-eu_dev-eu-1", now.Add(time.Hour)); err != nil {
+				t.Fatalf("Could not create trash, please check test code: %v", err)
+			}
+
+			rc := newRestoreCmd()
+			rc.sm = sm
+			rc.prompt = tc.pf
+
+			err := rc.restore(rc.cmd, tc.args)
+			if !testhelper.EqualError(tc.expErr, err) {
+				t.Errorf("Exp err %q, got %q", tc.expErr, err)
+			}
+
+			if tc.expRestore != "" {
+				if _, err := f.Stat(sm.StorePathFromID(tc.expRestore)); err != nil {
+					t.Errorf("Exp konf %q to be restored, but it is not in the store: %v", tc.expRestore, err)
+				}
+			}
+		})
+	}
+}
+
+func TestRestoreEmptyTrash(t *testing.T) {
+	sm := &store.Storemanager{Fs: afero.NewMemMapFs(), Trashdir: "./konf/trash"}
+	rc := newRestoreCmd()
+	rc.sm = sm
+
+	expErr := fmt.Errorf("there is nothing to restore, the trash is empty")
+	if err := rc.restore(rc.cmd, []string{}); !testhelper.EqualError(expErr, err) {
+		t.Errorf("Exp err %q, got %q", expErr, err)
+	}
+}
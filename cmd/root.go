@@ -4,6 +4,8 @@ import (
 	"flag"
 	"io"
 	"os"
+	"regexp"
+	"strings"
 
 	"github.com/simontheleg/konf-go/config"
 	"github.com/simontheleg/konf-go/log"
@@ -48,6 +50,7 @@ func Execute() error {
 		return err
 	}
 
+	rootCmd.SetArgs(expandSetShorthand(os.Args[1:]))
 	if err := rootCmd.Execute(); err != nil {
 		return err
 	}
@@ -77,6 +80,54 @@ func initPersistentFlags() error {
 	return nil
 }
 
+var setShorthand = regexp.MustCompile(`^-[0-9]+$`)
+
+// globalValueFlags and setValueFlags are the flags that take a value as a
+// separate argument. They are needed to tell flag values from positional
+// arguments in expandSetShorthand
+var (
+	globalValueFlags = map[string]bool{"--konf-dir": true, "-konf-dir": true}
+	setValueFlags    = map[string]bool{"-n": true, "--namespace": true, "--primary": true, "--back": true}
+)
+
+// expandSetShorthand rewrites 'set -N' into 'set --back=N'. This is required
+// because pflag would otherwise try to parse '-N' as a series of shorthand
+// flags and fail. Only the first positional argument of the set command is
+// rewritten, so flag values like in 'set -n -3' are left untouched
+func expandSetShorthand(args []string) []string {
+	out := append([]string{}, args...)
+
+	// global flags come before the subcommand, see initPersistentFlags
+	i := 0
+	for ; i < len(out) && strings.HasPrefix(out[i], "-") && out[i] != "--"; i++ {
+		if globalValueFlags[out[i]] {
+			i++
+		}
+	}
+	if i >= len(out) || out[i] != "set" {
+		return out
+	}
+
+	for i++; i < len(out); i++ {
+		a := out[i]
+		switch {
+		case a == "--":
+			return out
+		case setShorthand.MatchString(a):
+			out[i] = "--back=" + strings.TrimPrefix(a, "-")
+			return out
+		case strings.HasPrefix(a, "-"):
+			if setValueFlags[a] {
+				i++
+			}
+		default:
+			// the first positional argument is a konf, so there is nothing to rewrite
+			return out
+		}
+	}
+	return out
+}
+
 func initConfig() error {
 	conf, err := config.DefaultConfig()
 	if err != nil {
@@ -92,17 +143,34 @@ func initConfig() error {
 		log.InitLogger(io.Discard, io.Discard)
 	}
 
+	// the config file lives in the konfDir, so it can only be loaded once we know where that is
+	if err := config.LoadFile(afero.NewOsFs(), conf); err != nil {
+		return err
+	}
+
 	config.SetGlobalConfig(conf)
 	return nil
 }
 
 func initCommands() {
-	rootCmd.AddCommand(cleanupCmd)
+	rootCmd.AddCommand(newCleanupCmd().cmd)
 	rootCmd.AddCommand(newCompletionCmd().cmd)
+	rootCmd.AddCommand(newCurrentCmd().cmd)
 	rootCmd.AddCommand(newDeleteCommand().cmd)
+	rootCmd.AddCommand(newDiffCmd().cmd)
+	rootCmd.AddCommand(newHistoryCmd().cmd)
 	rootCmd.AddCommand(newImportCmd().cmd)
+	rootCmd.AddCommand(newListCmd().cmd)
+	rootCmd.AddCommand(newMetaCmd().cmd)
 	rootCmd.AddCommand(newNamespaceCmd().cmd)
+	rootCmd.AddCommand(newPinCmd().cmd)
+	rootCmd.AddCommand(newPromptCmd().cmd)
+	rootCmd.AddCommand(newRestoreCmd().cmd)
+	rootCmd.AddCommand(newSessionsCmd().cmd)
 	rootCmd.AddCommand(newSetCommand().cmd)
 	rootCmd.AddCommand(newShellwrapperCmd().cmd)
+	rootCmd.AddCommand(newSyncCmd().cmd)
+	rootCmd.AddCommand(newTrashCmd().cmd)
+	rootCmd.AddCommand(newUnpinCmd().cmd)
 	rootCmd.AddCommand(newVersionCommand().cmd)
 }
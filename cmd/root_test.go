@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExpandSetShorthand(t *testing.T) {
+	tt := map[string]struct {
+		args []string
+		exp  []string
+	}{
+		"set -N": {
+			[]string{"set", "-2"},
+			[]string{"set", "--back=2"},
+		},
+		"set -N with other flags": {
+			[]string{"--silent", "set", "-12", "-n", "kube-system"},
+			[]string{"--silent", "set", "--back=12", "-n", "kube-system"},
+		},
+		"set -": {
+			[]string{"set", "-"},
+			[]string{"set", "-"},
+		},
+		"other command": {
+			[]string{"delete", "-2"},
+			[]string{"delete", "-2"},
+		},
+		"global flag with separate value": {
+			[]string{"--konf-dir", "/tmp/konfs", "set", "-3"},
+			[]string{"--konf-dir", "/tmp/konfs", "set", "--back=3"},
+		},
+		"flag value": {
+			[]string{"set", "-n", "-3", "-2"},
+			[]string{"set", "-n", "-3", "--back=2"},
+		},
+		"not the first positional argument": {
+			[]string{"set", "dev-eu", "-2"},
+			[]string{"set", "dev-eu", "-2"},
+		},
+		"set after other arguments": {
+			[]string{"ns", "set", "-2"},
+			[]string{"ns", "set", "-2"},
+		},
+		"after double dash": {
+			[]string{"set", "--", "-2"},
+			[]string{"set", "--", "-2"},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			res := expandSetShorthand(tc.args)
+			if !cmp.Equal(res, tc.exp) {
+				t.Errorf("Exp args %v, got %v", tc.exp, res)
+			}
+		})
+	}
+}
@@ -0,0 +1,522 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mitchellh/go-ps"
+	"github.com/simontheleg/konf-go/config"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/log"
+	"github.com/simontheleg/konf-go/prompt"
+	"github.com/simontheleg/konf-go/store"
+	"github.com/simontheleg/konf-go/utils"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	k8s "k8s.io/client-go/tools/clientcmd/api/v1"
+	"sigs.k8s.io/yaml"
+)
+
+type sessionsCmd struct {
+	sm      *store.Storemanager
+	isAlive func(konf.KonfID) (bool, error)
+
+	cmd *cobra.Command
+}
+
+func newSessionsCmd() *sessionsCmd {
+	fs := afero.NewOsFs()
+	sm := &store.Storemanager{Fs: fs, Activedir: config.ActiveDir(), Storedir: config.StoreDir(), Statedir: config.StateDir()}
+	sc := &sessionsCmd{
+		sm:      sm,
+		isAlive: sessionIsAlive,
+	}
+
+	sc.cmd = &cobra.Command{
+		Use:   "sessions",
+		Short: "List shells that use a kubeconfig",
+		Long: `List all shells that currently use a konf. The session of the current shell is marked with an asterisk.
+
+Sessions of shells that have been closed are not listed. Use 'konf cleanup' to remove their leftovers`,
+		RunE: sc.list,
+		Args: cobra.NoArgs,
+	}
+
+	sc.cmd.AddCommand(newSessionsKillCmd(sc).cmd)
+	sc.cmd.AddCommand(newSessionsCheckCmd(sc).cmd)
+
+	return sc
+}
+
+func (c *sessionsCmd) list(cmd *cobra.Command, args []string) error {
+	sessions, err := liveSessions(c.sm, c.isAlive)
+	if err != nil {
+		return err
+	}
+
+	own := currentSessionID()
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  PID\tKONF\tNAMESPACE\tSHELL\tTTY\tSTARTED")
+	for _, s := range sessions {
+		marker := " "
+		if s.ID == own {
+			marker = "*"
+		}
+		started := "unknown"
+		if !s.Started.IsZero() {
+			started = prompt.TimeAgo(s.Started, now)
+		}
+		ids := []string{}
+		for _, id := range s.Konfs {
+			ids = append(ids, string(id))
+		}
+		fmt.Fprintf(w, "%s %s\t%s\t%s\t%s\t%s\t%s\n", marker, sessionPID(s.ID), strings.Join(ids, ","), orDefault(s.Namespace, "default"), orDefault(s.Shell, "-"), orDefault(s.TTY, "-"), started)
+	}
+
+	return w.Flush()
+}
+
+type sessionsKillCmd struct {
+	parent     *sessionsCmd
+	confirm    prompt.ConfirmFunc
+	isTerminal func() bool
+
+	yes bool
+
+	cmd *cobra.Command
+}
+
+func newSessionsKillCmd(parent *sessionsCmd) *sessionsKillCmd {
+	kc := &sessionsKillCmd{
+		parent:     parent,
+		confirm:    prompt.Confirm,
+		isTerminal: prompt.IsTerminal,
+	}
+
+	kc.cmd = &cobra.Command{
+		Use:   "kill",
+		Short: "Revoke the kubeconfig of a shell",
+		Long: `Revoke the active konf of one or multiple shells. Afterwards these shells cannot access their cluster anymore until 'konf set' is run again.
+
+Examples:
+-> 'sessions kill <pid>' revoke the active konf of the shell with this PID
+-> 'sessions kill <konfig id>' revoke the active konf of all shells that use this konf
+
+Revoking multiple sessions has to be confirmed. When not run interactively,
+multiple sessions are only revoked if --yes is given
+`,
+		RunE:              kc.kill,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: kc.completeKill,
+	}
+
+	kc.cmd.Flags().BoolVarP(&kc.yes, "yes", "y", false, "revoke multiple sessions without asking for confirmation (default is false)")
+
+	return kc
+}
+
+func (c *sessionsKillCmd) kill(cmd *cobra.Command, args []string) error {
+	matches, err := matchingSessions(c.parent.sm, args[0], c.parent.isAlive)
+	if err != nil {
+		return err
+	}
+
+	if len(matches) > 1 && !c.yes {
+		if !c.isTerminal() {
+			return fmt.Errorf("refusing to revoke %d sessions without confirmation, use --yes to revoke them anyway", len(matches))
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "The konf of the following %d session(s) will be revoked:", len(matches))
+		for _, s := range matches {
+			fmt.Fprintf(&b, "\n  %s", s.ID)
+		}
+		log.Info("%s", b.String())
+
+		ok, err := c.confirm(fmt.Sprintf("Revoke %d session(s)", len(matches)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.Info("Revoking aborted")
+			return nil
+		}
+	}
+
+	if err := killSessions(c.parent.sm, matches); err != nil {
+		return err
+	}
+	for _, s := range matches {
+		log.Info("Revoked konf of session %s", s.ID)
+	}
+	return nil
+}
+
+func (c *sessionsKillCmd) completeKill(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return []string{}, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	sessions, err := liveSessions(c.parent.sm, c.parent.isAlive)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	sug := []string{}
+	for _, s := range sessions {
+		sug = append(sug, sessionPID(s.ID))
+		for _, id := range s.Konfs {
+			if !slices.Contains(sug, string(id)) {
+				sug = append(sug, string(id))
+			}
+		}
+	}
+
+	return sug, cobra.ShellCompDirectiveNoFileComp
+}
+
+type sessionsCheckCmd struct {
+	parent *sessionsCmd
+
+	cmd *cobra.Command
+}
+
+func newSessionsCheckCmd(parent *sessionsCmd) *sessionsCheckCmd {
+	cc := &sessionsCheckCmd{
+		parent: parent,
+	}
+
+	cc.cmd = &cobra.Command{
+		Use:   "check",
+		Short: "Revoke the kubeconfig of the current shell once it has expired",
+		Long: `Revoke the active konf of the current shell once it has expired and warn about it.
+The shellwrapper runs this before every prompt if it has been created with --check-expiry, so it is not meant to be run manually`,
+		RunE:   cc.check,
+		Args:   cobra.NoArgs,
+		Hidden: true,
+	}
+
+	return cc
+}
+
+func (c *sessionsCheckCmd) check(cmd *cobra.Command, args []string) error {
+	kPath := os.Getenv("KUBECONFIG")
+	if kPath == "" {
+		return nil
+	}
+
+	// this runs before every prompt, so failures are only logged instead of printing the usage every time
+	unusable, err := revokeIfExpired(c.parent.sm, kPath, time.Now())
+	if err != nil {
+		log.Warn("Could not check whether the konf of this shell has expired: %v", err)
+		return nil
+	}
+	if unusable {
+		log.Warn("The konf of this shell has expired or has been revoked. Run 'konf set' to use a konf again")
+		// tells the shellwrapper to stop checking until another konf is set. If you change this part in
+		// here, do not forget to update shellwrapper.go
+		fmt.Println("KUBECONFIGREVOKED")
+	}
+	return nil
+}
+
+// revokeIfExpired revokes the session the kubeconfig at kPath belongs to, if
+// it has expired. It returns true if the kubeconfig cannot be used anymore,
+// either because it has just expired or because it has been removed before.
+// Kubeconfigs that are not managed by konf are never touched
+func revokeIfExpired(sm *store.Storemanager, kPath string, now time.Time) (bool, error) {
+	id, ok := sessionFromKubeconfig(sm, kPath)
+	if !ok {
+		return false, nil
+	}
+	if _, err := sm.Fs.Stat(kPath); errors.Is(err, fs.ErrNotExist) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	ses, err := sm.Session(id)
+	if err != nil {
+		return false, err
+	}
+	if ses == nil || !ses.Expired(now) {
+		return false, nil
+	}
+
+	return true, sm.RemoveSession(id)
+}
+
+// liveSessions returns all sessions whose shell is still running
+func liveSessions(sm *store.Storemanager, isAlive func(konf.KonfID) (bool, error)) ([]*store.Session, error) {
+	sessions, err := sm.Sessions()
+	if err != nil {
+		return nil, err
+	}
+
+	live := []*store.Session{}
+	for _, s := range sessions {
+		// without a process id, konf cannot tell whether the shell is still running
+		if _, _, err := konf.ProcessFromID(s.ID); err != nil {
+			live = append(live, s)
+			continue
+		}
+
+		alive, err := isAlive(s.ID)
+		if err != nil {
+			return nil, err
+		}
+		if alive {
+			live = append(live, s)
+		}
+	}
+
+	return live, nil
+}
+
+// matchingSessions returns the live session whose ID or PID is target. If
+// there is no such session, target is treated as a konf ID and all live
+// sessions using that konf are returned instead
+func matchingSessions(sm *store.Storemanager, target string, isAlive func(konf.KonfID) (bool, error)) ([]*store.Session, error) {
+	sessions, err := liveSessions(sm, isAlive)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*store.Session
+	for _, s := range sessions {
+		if s.ID == konf.KonfID(target) || sessionPID(s.ID) == target {
+			matches = []*store.Session{s}
+			break
+		}
+		if slices.Contains(s.Konfs, konf.KonfID(target)) {
+			matches = append(matches, s)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no session matches %q", target)
+	}
+
+	return matches, nil
+}
+
+// killSessions revokes the supplied sessions by removing their active konfs
+func killSessions(sm *store.Storemanager, sessions []*store.Session) error {
+	for _, s := range sessions {
+		if err := sm.RemoveSession(s.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forkSession makes sure the kubeconfig at kPath can be modified without
+// affecting other shells. A nested shell (e.g. a tmux split or a subshell)
+// inherits $KUBECONFIG from the shell it has been started from, so it points
+// to the active konf of another session. In that case the active konf is copied
+// for the session with the supplied id and the path of the copy is returned,
+// together with true. The caller is responsible for passing the new path on to
+// the shellwrapper. Kubeconfigs that are not managed by konf are never copied
+func forkSession(sm *store.Storemanager, kPath string, id konf.KonfID) (string, bool, error) {
+	parent, ok := sessionFromKubeconfig(sm, kPath)
+	if !ok || parent == id {
+		return kPath, false, nil
+	}
+
+	b, err := afero.ReadFile(sm.Fs, kPath)
+	if err != nil {
+		return "", false, err
+	}
+	own := sm.ActivePathFromID(id)
+	if err := utils.WriteFileAtomic(sm.Fs, own, b, utils.KonfPerm); err != nil {
+		return "", false, err
+	}
+	recordSession(sm, id, b)
+
+	// the copy must not outlive the konf it has been copied from
+	if ps, err := sm.Session(parent); err == nil && ps != nil && !ps.Expires.IsZero() {
+		if ses, err := sm.Session(id); err == nil && ses != nil {
+			ses.Expires = ps.Expires
+			if err := sm.WriteSession(ses); err != nil {
+				log.Warn("Could not record expiry of session: %v", err)
+			}
+		}
+	}
+
+	return own, true, nil
+}
+
+// sessionFromKubeconfig returns the ID of the session the kubeconfig at kPath
+// belongs to. If kPath is not an active konf, false is returned
+func sessionFromKubeconfig(sm *store.Storemanager, kPath string) (konf.KonfID, bool) {
+	if filepath.Clean(filepath.Dir(kPath)) != filepath.Clean(sm.Activedir) || filepath.Ext(kPath) != ".yaml" {
+		return "", false
+	}
+	return konf.KonfID(strings.TrimSuffix(filepath.Base(kPath), ".yaml")), true
+}
+
+// sessionExpiry returns when a session using the konfs with the supplied ids
+// expires. If multiple konfs have a TTL, the shortest one is used. If none of
+// them has a TTL, the zero time is returned
+func sessionExpiry(sm *store.Storemanager, ids []konf.KonfID, now time.Time) (time.Time, error) {
+	var expires time.Time
+	for _, id := range ids {
+		m, err := sm.Meta(id)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if m.TTL == "" {
+			continue
+		}
+
+		ttl, err := parseAge(m.TTL)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid ttl of konf %q: %v", id, err)
+		}
+		if e := now.Add(ttl); expires.IsZero() || e.Before(expires) {
+			expires = e
+		}
+	}
+	return expires, nil
+}
+
+// recordSession records the metadata of the session of the current shell
+// after its active konf has been written. Recording is best effort, as the
+// metadata is only informational
+func recordSession(sm *store.Storemanager, id konf.KonfID, kubeconfig []byte) {
+	now := time.Now()
+	ses, err := sm.Session(id)
+	if err != nil || ses == nil {
+		ses = &store.Session{ID: id, Started: now}
+	}
+
+	var conf k8s.Config
+	if err := yaml.Unmarshal(kubeconfig, &conf); err == nil {
+		ses.Konfs = konf.IDsFromKubeconfig(&conf)
+		if con, err := konf.CurrentContext(&conf); err == nil {
+			ses.Namespace = con.Context.Namespace
+		}
+	}
+
+	// every 'konf set' starts the TTL anew
+	ses.Expires, err = sessionExpiry(sm, ses.Konfs, now)
+	if err != nil {
+		log.Warn("Could not determine expiry of session. As a result it will not expire: %v", err)
+	}
+
+	// sessions named after a token know the PID of their shell, which is not necessarily our parent
+	pid := os.Getppid()
+	if p, _, err := konf.ProcessFromID(id); err == nil {
+		pid = p
+	}
+	if p, err := ps.FindProcess(pid); err == nil && p != nil {
+		ses.Shell = p.Executable()
+	}
+	ses.TTY = ttyName()
+
+	if err := sm.WriteSession(ses); err != nil {
+		log.Warn("Could not record session. As a result 'konf sessions' might not show all details and the session will not expire: %v", err)
+	}
+}
+
+// ttyName returns the terminal konf is run in. As the shellwrapper passes its
+// stdin on to konf, this is the terminal of the shell. If it cannot be
+// determined, an empty string is returned
+func ttyName() string {
+	if !prompt.IsTerminal() {
+		return ""
+	}
+	for _, fd := range []string{"/proc/self/fd/0", "/dev/fd/0"} {
+		if tty, err := os.Readlink(fd); err == nil && strings.HasPrefix(tty, "/dev/") {
+			return tty
+		}
+	}
+	return ""
+}
+
+// sessionEnv is the environment variable the shellwrapper uses to hand the
+// token of the current session to konf
+const sessionEnv = "KONF_SESSION"
+
+// currentSessionID returns the ID of the session of the shell konf is run in.
+// If the shellwrapper has set a session token, the ID is based on it.
+// Otherwise the parent process is assumed to be the shell, which is not true
+// when konf is run through tools like sudo or env. Where available, the ID
+// contains the start time of the shell
+func currentSessionID() konf.KonfID {
+	if token := os.Getenv(sessionEnv); token != "" {
+		id, err := konf.IDFromSessionToken(token, utils.ProcessStartTime)
+		if err == nil {
+			return id
+		}
+		log.Warn("Ignoring $%s: %v", sessionEnv, err)
+	}
+
+	ppid := os.Getppid()
+	start, err := utils.ProcessStartTime(ppid)
+	if err != nil {
+		// without the start time the session can still be identified, just not as reliably
+		start = ""
+	}
+	return konf.IDFromProcess(ppid, start)
+}
+
+// sessionIsAlive returns true if the shell of the session with the supplied id
+// is still running. If the id contains the start time of the shell, the
+// running process must have been started at the same time. Otherwise its PID
+// has just been reused by another process
+func sessionIsAlive(id konf.KonfID) (bool, error) {
+	pid, start, err := konf.ProcessFromID(id)
+	if err != nil {
+		return false, err
+	}
+
+	p, err := ps.FindProcess(pid)
+	if err != nil {
+		return false, err
+	}
+	if p == nil {
+		return false, nil
+	}
+	// sessions created by older versions of konf can only be identified by their PID
+	if start == "" {
+		return true, nil
+	}
+
+	actual, err := utils.ProcessStartTime(pid)
+	if errors.Is(err, fs.ErrNotExist) {
+		// the process has exited in the meantime
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return actual == start, nil
+}
+
+// sessionPID returns the PID of the shell of the session with the supplied id.
+// For sessions that are not based on a PID, the id itself is returned instead
+func sessionPID(id konf.KonfID) string {
+	pid, _, err := konf.ProcessFromID(id)
+	if err != nil {
+		return string(id)
+	}
+	return strconv.Itoa(pid)
+}
+
+// orDefault returns s, or def if s is empty
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
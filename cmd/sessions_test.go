@@ -0,0 +1,324 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/store"
+	"github.com/simontheleg/konf-go/testhelper"
+	"github.com/simontheleg/konf-go/utils"
+	"github.com/spf13/afero"
+)
+
+// sessionsFS creates three sessions. The shell of session 300 has been closed
+func sessionsFS(t *testing.T) *store.Storemanager {
+	var skm testhelper.SampleKonfManager
+	sm := &store.Storemanager{Fs: afero.NewMemMapFs(), Activedir: "./konf/active", Storedir: "./konf/store", Statedir: "./konf/state"}
+
+	for id, k := range map[konf.KonfID]string{"100": skm.SingleClusterSingleContextEU(), "200": skm.SingleClusterSingleContextASIA(), "300": skm.SingleClusterSingleContextEU()} {
+		if err := afero.WriteFile(sm.Fs, sm.ActivePathFromID(id), []byte(k), utils.KonfPerm); err != nil {
+			t.Fatalf("Could not create active konf, please check test code: %v", err)
+		}
+	}
+	started := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	if err := sm.WriteSession(&store.Session{ID: "100", Konfs: []konf.KonfID{"dev-eu_dev-eu-1"}, Started: started, Shell: "zsh", TTY: "/dev/pts/1"}); err != nil {
+		t.Fatalf("Could not record session, please check test code: %v", err)
+	}
+
+	return sm
+}
+
+var mockIsAlive = func(id konf.KonfID) (bool, error) { return id != "300", nil }
+
+func TestLiveSessions(t *testing.T) {
+	sm := sessionsFS(t)
+
+	sessions, err := liveSessions(sm, mockIsAlive)
+	if err != nil {
+		t.Fatalf("Exp no error, got %v", err)
+	}
+
+	exp := []*store.Session{
+		// sessions without metadata have no start time and therefore come first
+		{ID: "200", Konfs: []konf.KonfID{"dev-asia_dev-asia-1"}, Namespace: "kube-public"},
+		{ID: "100", Konfs: []konf.KonfID{"dev-eu_dev-eu-1"}, Namespace: "kube-public", Started: time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC), Shell: "zsh", TTY: "/dev/pts/1"},
+	}
+	if !cmp.Equal(exp, sessions) {
+		t.Errorf("Exp sessions %v, got %v", exp, sessions)
+	}
+}
+
+func TestKillSessions(t *testing.T) {
+	tt := map[string]struct {
+		target    string
+		expKilled []konf.KonfID
+		expErr    error
+	}{
+		"kill by pid": {
+			"200",
+			[]konf.KonfID{"200"},
+			nil,
+		},
+		"kill by konf id": {
+			"dev-eu_dev-eu-1",
+			[]konf.KonfID{"100"},
+			nil,
+		},
+		"closed sessions are not killed": {
+			"300",
+			nil,
+			fmt.Errorf("no session matches %q", "300"),
+		},
+		"no match": {
+			"dev-us_dev-us-1",
+			nil,
+			fmt.Errorf("no session matches %q", "dev-us_dev-us-1"),
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			sm := sessionsFS(t)
+
+			killed, err := matchingSessions(sm, tc.target, mockIsAlive)
+			if !testhelper.EqualError(tc.expErr, err) {
+				t.Errorf("Exp err %q, got %q", tc.expErr, err)
+			}
+			if err := killSessions(sm, killed); err != nil {
+				t.Fatalf("Exp no error, got %v", err)
+			}
+
+			var ids []konf.KonfID
+			for _, s := range killed {
+				ids = append(ids, s.ID)
+				if _, err := sm.Fs.Stat(sm.ActivePathFromID(s.ID)); err == nil {
+					t.Errorf("Exp active konf of session %q to be removed", s.ID)
+				}
+				if ses, _ := sm.Session(s.ID); ses != nil {
+					t.Errorf("Exp metadata of session %q to be removed", s.ID)
+				}
+			}
+			if !cmp.Equal(tc.expKilled, ids) {
+				t.Errorf("Exp killed sessions %v, got %v", tc.expKilled, ids)
+			}
+		})
+	}
+}
+
+func TestSessionsKill(t *testing.T) {
+	tt := map[string]struct {
+		target     string
+		isTerminal bool
+		answer     bool
+		yes        bool
+		expConfirm bool
+		expKilled  []konf.KonfID
+		expErr     error
+	}{
+		"single session is not confirmed": {target: "200", expKilled: []konf.KonfID{"200"}},
+		"multiple sessions confirmed":     {target: "dev-eu_dev-eu-1", isTerminal: true, answer: true, expConfirm: true, expKilled: []konf.KonfID{"100", "400"}},
+		"multiple sessions aborted":       {target: "dev-eu_dev-eu-1", isTerminal: true, answer: false, expConfirm: true},
+		"multiple sessions with yes":      {target: "dev-eu_dev-eu-1", isTerminal: true, yes: true, expKilled: []konf.KonfID{"100", "400"}},
+		"multiple sessions without terminal": {
+			target: "dev-eu_dev-eu-1",
+			expErr: fmt.Errorf("refusing to revoke 2 sessions without confirmation, use --yes to revoke them anyway"),
+		},
+		"multiple sessions without terminal but yes": {target: "dev-eu_dev-eu-1", yes: true, expKilled: []konf.KonfID{"100", "400"}},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			var skm testhelper.SampleKonfManager
+			sm := sessionsFS(t)
+			if err := afero.WriteFile(sm.Fs, sm.ActivePathFromID("400"), []byte(skm.SingleClusterSingleContextEU()), utils.KonfPerm); err != nil {
+				t.Fatalf("Could not create active konf, please check test code: %v", err)
+			}
+
+			confirmed := false
+			kc := &sessionsKillCmd{
+				parent:     &sessionsCmd{sm: sm, isAlive: mockIsAlive},
+				confirm:    func(string) (bool, error) { confirmed = true; return tc.answer, nil },
+				isTerminal: func() bool { return tc.isTerminal },
+				yes:        tc.yes,
+			}
+
+			err := kc.kill(kc.cmd, []string{tc.target})
+			if !testhelper.EqualError(tc.expErr, err) {
+				t.Errorf("Exp err %q, got %q", tc.expErr, err)
+			}
+			if confirmed != tc.expConfirm {
+				t.Errorf("Exp confirmation to be asked: %t, got %t", tc.expConfirm, confirmed)
+			}
+
+			for _, id := range []konf.KonfID{"100", "200", "400"} {
+				_, err := sm.Fs.Stat(sm.ActivePathFromID(id))
+				if killed := slices.Contains(tc.expKilled, id); killed != (err != nil) {
+					t.Errorf("Exp session %q to be revoked: %t, got %t", id, killed, err != nil)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordSession(t *testing.T) {
+	var skm testhelper.SampleKonfManager
+	sm := sessionsFS(t)
+
+	// recording an existing session keeps its start time
+	recordSession(sm, "100", []byte(skm.SingleClusterSingleContextASIA()))
+	ses, err := sm.Session("100")
+	if err != nil {
+		t.Fatalf("Exp no error, got %v", err)
+	}
+	if exp := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC); !ses.Started.Equal(exp) {
+		t.Errorf("Exp start time %v to be kept, got %v", exp, ses.Started)
+	}
+	if exp := []konf.KonfID{"dev-asia_dev-asia-1"}; !cmp.Equal(exp, ses.Konfs) {
+		t.Errorf("Exp konfs %v, got %v", exp, ses.Konfs)
+	}
+
+	recordSession(sm, "400", []byte(skm.SingleClusterSingleContextEU()))
+	ses, err = sm.Session("400")
+	if err != nil || ses == nil {
+		t.Fatalf("Exp session to be recorded, got %v (err: %v)", ses, err)
+	}
+	if ses.Started.IsZero() || ses.Namespace != "kube-public" {
+		t.Errorf("Exp start time and namespace to be recorded, got %+v", ses)
+	}
+	if !ses.Expires.IsZero() {
+		t.Errorf("Exp session of a konf without ttl to never expire, got %v", ses.Expires)
+	}
+
+	// setting a konf with a ttl starts it anew
+	sm.SetMeta("dev-eu_dev-eu-1", &store.KonfMeta{TTL: "1h"})
+	before := time.Now()
+	recordSession(sm, "400", []byte(skm.SingleClusterSingleContextEU()))
+	ses, _ = sm.Session("400")
+	if ses.Expires.Before(before.Add(time.Hour)) || ses.Expires.After(time.Now().Add(time.Hour)) {
+		t.Errorf("Exp session to expire in an hour, got %v", ses.Expires)
+	}
+}
+
+func TestCurrentSessionID(t *testing.T) {
+	fallback := konf.IDFromProcessID(os.Getppid())
+
+	tt := map[string]struct {
+		token string
+		exp   func(konf.KonfID) bool
+	}{
+		"token is used": {"vscode_1", func(id konf.KonfID) bool { return id == "vscode_1" }},
+		"no token":      {"", func(id konf.KonfID) bool { return strings.HasPrefix(string(id), string(fallback)) }},
+		"invalid token": {"../vscode", func(id konf.KonfID) bool { return strings.HasPrefix(string(id), string(fallback)) }},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(sessionEnv, tc.token)
+			if id := currentSessionID(); !tc.exp(id) {
+				t.Errorf("Unexpected session id %q", id)
+			}
+		})
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	sm := &store.Storemanager{Fs: afero.NewMemMapFs(), Statedir: "./konf/state"}
+	sm.SetMeta("prod", &store.KonfMeta{TTL: "1h"})
+	sm.SetMeta("prod-db", &store.KonfMeta{TTL: "30m"})
+	sm.SetMeta("broken", &store.KonfMeta{TTL: "soon"})
+
+	tt := map[string]struct {
+		ids    []konf.KonfID
+		exp    time.Time
+		expErr bool
+	}{
+		"no ttl":            {[]konf.KonfID{"dev"}, time.Time{}, false},
+		"single ttl":        {[]konf.KonfID{"dev", "prod"}, now.Add(time.Hour), false},
+		"shortest ttl wins": {[]konf.KonfID{"prod", "prod-db"}, now.Add(30 * time.Minute), false},
+		"invalid ttl":       {[]konf.KonfID{"broken"}, time.Time{}, true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			res, err := sessionExpiry(sm, tc.ids, now)
+			if (err != nil) != tc.expErr {
+				t.Errorf("Exp error to be %t, got %v", tc.expErr, err)
+			}
+			if !res.Equal(tc.exp) {
+				t.Errorf("Exp expiry %v, got %v", tc.exp, res)
+			}
+		})
+	}
+}
+
+func TestRevokeIfExpired(t *testing.T) {
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tt := map[string]struct {
+		kubeenv     string
+		expires     time.Time
+		expUnusable bool
+		expRemoved  bool
+	}{
+		"never expires":            {"./konf/active/100.yaml", time.Time{}, false, false},
+		"not yet expired":          {"./konf/active/100.yaml", now.Add(time.Minute), false, false},
+		"expired":                  {"./konf/active/100.yaml", now.Add(-time.Minute), true, true},
+		"revoked before":           {"./konf/active/200.yaml", time.Time{}, true, false},
+		"kubeconfig not from konf": {"./kube/config", now.Add(-time.Minute), false, false},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			sm := sessionsFS(t)
+			if err := sm.Fs.Remove(sm.ActivePathFromID("200")); err != nil {
+				t.Fatal(err)
+			}
+			if err := sm.WriteSession(&store.Session{ID: "100", Expires: tc.expires}); err != nil {
+				t.Fatal(err)
+			}
+
+			unusable, err := revokeIfExpired(sm, tc.kubeenv, now)
+			if err != nil {
+				t.Fatalf("Exp no error, got %v", err)
+			}
+			if unusable != tc.expUnusable {
+				t.Errorf("Exp unusable to be %t, got %t", tc.expUnusable, unusable)
+			}
+			_, err = sm.Fs.Stat(sm.ActivePathFromID("100"))
+			if removed := err != nil; removed != tc.expRemoved {
+				t.Errorf("Exp active konf to be removed: %t, got %t", tc.expRemoved, removed)
+			}
+		})
+	}
+}
+
+func TestForkSessionInheritsExpiry(t *testing.T) {
+	sm := sessionsFS(t)
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	if err := sm.WriteSession(&store.Session{ID: "100", Expires: expires}); err != nil {
+		t.Fatal(err)
+	}
+
+	path, forked, err := forkSession(sm, sm.ActivePathFromID("100"), "child_1")
+	if err != nil {
+		t.Fatalf("Exp no error, got %v", err)
+	}
+	if !forked || path != sm.ActivePathFromID("child_1") {
+		t.Errorf("Exp konf to be copied to %q, got %q (forked: %t)", sm.ActivePathFromID("child_1"), path, forked)
+	}
+
+	ses, err := sm.Session("child_1")
+	if err != nil || ses == nil {
+		t.Fatalf("Exp session to be recorded, got %v (err: %v)", ses, err)
+	}
+	if !ses.Expires.Equal(expires) {
+		t.Errorf("Exp copy to expire with its parent at %v, got %v", expires, ses.Expires)
+	}
+}
@@ -5,6 +5,10 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/manifoldco/promptui"
 	"github.com/simontheleg/konf-go/config"
@@ -15,36 +19,64 @@ import (
 	"github.com/simontheleg/konf-go/utils"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
 )
 
 type setCmd struct {
 	sm *store.Storemanager
 
+	clientSetFromFile func(afero.Fs, string) (kubernetes.Interface, error)
+	prompt            prompt.RunFunc
+	confirm           prompt.ConfirmFunc
+	isTerminal        func() bool
+	startCleanup      func() error
+
+	primary   string
+	namespace string
+	back      int
+
 	cmd *cobra.Command
 }
 
 func newSetCommand() *setCmd {
 	fs := afero.NewOsFs()
-	sm := &store.Storemanager{Fs: fs, Activedir: config.ActiveDir(), Storedir: config.StoreDir(), LatestKonfPath: config.LatestKonfFilePath()}
+	sm := &store.Storemanager{Fs: fs, Activedir: config.ActiveDir(), Storedir: config.StoreDir(), Statedir: config.StateDir(), LatestKonfPath: config.LatestKonfFilePath()}
 	sc := &setCmd{
-		sm: sm,
+		sm:                sm,
+		clientSetFromFile: newKubeClientSetFromFile,
+		prompt:            prompt.Configured(),
+		confirm:           prompt.Confirm,
+		isTerminal:        prompt.IsTerminal,
+		startCleanup:      startBackgroundCleanup,
 	}
 
 	sc.cmd = &cobra.Command{
 		Use:   `set`,
 		Short: "Set kubeconfig to use in current shell",
-		Args:  cobra.MaximumNArgs(1),
+		Args:  cobra.ArbitraryArgs,
 		Long: `Sets kubeconfig to use or start picker dialogue.
 	
 Examples:
 -> 'set' run konf selection
 -> 'set <konfig id>' set a specific konf
+-> 'set <query>' set the konf matching an alias, a glob or a fuzzy search. Opens a selection if multiple konfs match
 -> 'set -' set to last used konf
+-> 'set -2' set to second to last used konf. Works for any number N
+-> 'set <konfig id> --namespace <namespace>' set a specific konf and namespace
+-> 'set <konfig id>/<namespace>' shorthand for the above
+-> 'set <konfig id> <konfig id 2> --primary <konfig id 2>' merge multiple konfs into one session
 `,
 		RunE:              sc.set,
 		ValidArgsFunction: sc.completeSet,
 	}
 
+	sc.cmd.Flags().StringVar(&sc.primary, "primary", "", "konf to use as current-context when setting multiple konfs (default is the first konf)")
+	sc.cmd.Flags().StringVarP(&sc.namespace, "namespace", "n", "", "namespace to set in the konf. For multiple konfs it is set in the primary konf")
+	sc.cmd.RegisterFlagCompletionFunc("namespace", sc.completeNamespaceFlag)
+	// users are not expected to use this flag directly. Instead 'set -N' is rewritten into it, see expandSetShorthand
+	sc.cmd.Flags().IntVar(&sc.back, "back", 0, "set the N-th most recently used konf. Can also be written as 'set -N'")
+
 	return sc
 }
 
@@ -53,42 +85,151 @@ func (c *setCmd) set(cmd *cobra.Command, args []string) error {
 	// namespace. This part should be refactored to allow for mocking
 	// the downstream funcs in order to test the if-else logic
 	var id konf.KonfID
+	var context string
 	var err error
+	ns := c.namespace
 
-	if len(args) == 0 {
-		id, err = selectSingleKonf(c.sm, prompt.Terminal)
+	if len(args) > 1 {
+		if c.back > 0 {
+			return fmt.Errorf("'set -%d' cannot be combined with multiple konfs", c.back)
+		}
+		ids, namespaces, err := resolveMergeArgs(c.sm, args, c.prompt, c.confirm, c.isTerminal())
 		if err != nil {
 			return err
 		}
-	} else if args[0] == "-" {
-		id, err = idOfLatestKonf(c.sm)
+		id = ids[0]
+		if c.primary != "" {
+			id, err = resolvePrimary(c.sm, c.primary, ids, c.prompt, c.isTerminal())
+			if err != nil {
+				return err
+			}
+		}
+
+		// the namespace of the primary konf is set the same way as for a single konf below
+		if shortNs := namespaces[id]; shortNs != "" {
+			if ns != "" && ns != shortNs {
+				return fmt.Errorf("namespace has been supplied twice with different values: %q and %q", shortNs, ns)
+			}
+			ns = shortNs
+			delete(namespaces, id)
+		}
+
+		// the same konf might have been supplied multiple times, e.g. by its ID and an alias. Merging it with
+		// itself would rename its cluster and context, so it is set like a single konf instead
+		if len(ids) == 1 {
+			context, err = setContext(id, c.sm)
+		} else {
+			context, err = setMergedContext(ids, id, namespaces, c.sm)
+		}
 		if err != nil {
 			return err
 		}
 	} else {
-		id = konf.KonfID(args[0])
+		if len(args) == 1 {
+			var shortNs string
+			id, shortNs = splitIDAndNamespace(args[0])
+			if shortNs != "" {
+				if ns != "" && ns != shortNs {
+					return fmt.Errorf("namespace has been supplied twice with different values: %q and %q", shortNs, ns)
+				}
+				ns = shortNs
+			}
+		}
+		query := string(id)
+
+		if c.back > 0 {
+			if len(args) != 0 {
+				return fmt.Errorf("'set -%d' cannot be combined with a konf id", c.back)
+			}
+			id, err = idOfLatestKonf(c.sm, c.back)
+			if err != nil {
+				return err
+			}
+		} else if len(args) == 0 {
+			id, err = selectSingleKonf(c.sm, c.prompt)
+			if err != nil {
+				return err
+			}
+		} else if id == "-" {
+			id, err = idOfLatestKonf(c.sm, 1)
+			if err != nil {
+				return err
+			}
+		} else {
+			id, err = resolveKonfID(c.sm, string(id), c.prompt, c.isTerminal())
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := confirmProtected(c.sm, id, query, c.confirm, c.isTerminal()); err != nil {
+			return err
+		}
+
+		context, err = setContext(id, c.sm)
+		if err != nil {
+			return err
+		}
 	}
 
-	context, err := setContext(id, c.sm)
-	if err != nil {
+	// the namespace needs to be written before we hand the konf over to the shellwrapper.
+	// Otherwise the first command in the new konf could still run in the old namespace
+	if ns != "" {
+		if err := setNamespaceInFile(c.sm.Fs, context, ns); err != nil {
+			return err
+		}
+		if err := c.sm.AddToNamespaceHistory(id, ns); err != nil {
+			return fmt.Errorf("could not save namespace history. As a result 'konf ns -' might not work: %q ", err)
+		}
+		log.Info("Setting namespace to %q\n", ns)
+	}
+
+	if err := finishSet(c.sm, id, context); err != nil {
 		return err
 	}
-	err = saveLatestKonf(c.sm, id)
+
+	lazyCleanup(c.sm, config.CleanupInterval(), time.Now(), c.startCleanup)
+	return nil
+}
+
+// finishSet saves the konf with the supplied id as latest konf and passes the
+// new kubeconfig at context on to the shellwrapper
+func finishSet(sm *store.Storemanager, id konf.KonfID, context string) error {
+	err := saveLatestKonf(sm, id)
 	if err != nil {
 		return fmt.Errorf("could not save latest konf. As a result 'konf set -' might not work: %q ", err)
 	}
 
+	err = sm.RecordUsage(id, time.Now())
+	if err != nil {
+		return fmt.Errorf("could not save usage of konf. As a result the selection prompt might not be ordered correctly: %q ", err)
+	}
+
 	log.Info("Setting context to %q\n", id)
+	changeKubeconfig(context)
+
+	return nil
+}
 
+// changeKubeconfig instructs the shellwrapper to point $KUBECONFIG to path
+func changeKubeconfig(path string) {
 	// By printing out to stdout, we pass the value to our zsh hook, which then sets $KUBECONFIG to it
 	// Both operate on the convention to use "KUBECONFIGCHANGE:<new-path>". If you change this part in
 	// here, do not forget to update shellwraper.go
-	fmt.Println("KUBECONFIGCHANGE:" + context)
-
-	return nil
+	fmt.Println("KUBECONFIGCHANGE:" + path)
 }
 
 func (c *setCmd) completeSet(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	// once the user has typed a slash, the konf is decided and we can offer its namespaces
+	if id, _, found := strings.Cut(toComplete, namespaceSeparator); found {
+		nss, directive := c.namespacesForKonf(konf.KonfID(id))
+		sug := []string{}
+		for _, ns := range nss {
+			sug = append(sug, id+namespaceSeparator+ns)
+		}
+		return sug, directive
+	}
+
 	konfs, err := c.sm.FetchAllKonfs()
 	if err != nil {
 		// if the store is just empty, return no suggestions, instead of throwing an error
@@ -104,12 +245,61 @@ func (c *setCmd) completeSet(cmd *cobra.Command, args []string, toComplete strin
 	for _, k := range konfs {
 		// with the current design of 'set', we need to return the ID here in the autocomplete as the first part of the completion
 		// as it is directly passed to set
-		sug = append(sug, string(konf.IDFromClusterAndContext(k.Cluster, k.Context)))
+		id := string(konf.IDFromClusterAndContext(k.Cluster, k.Context))
+		// konfs that are already part of a merged set should not be suggested twice
+		if slices.Contains(args, id) {
+			continue
+		}
+		sug = append(sug, id)
 	}
 
 	return sug, cobra.ShellCompDirectiveNoFileComp
 }
 
+func (c *setCmd) completeNamespaceFlag(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	// for multiple konfs the namespace is set in the primary konf
+	id := c.primary
+	if id == "" && len(args) > 0 {
+		id = args[0]
+	}
+	if id == "" || id == "-" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	konfID, _ := splitIDAndNamespace(id)
+	return c.namespacesForKonf(konfID)
+}
+
+// namespacesForKonf returns all namespaces for a konf in the store. It is meant
+// to be used in completion funcs
+func (c *setCmd) namespacesForKonf(id konf.KonfID) ([]string, cobra.ShellCompDirective) {
+	cs, err := c.clientSetFromFile(c.sm.Fs, c.sm.StorePathFromID(id))
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	nss, err := listNamespaces(cs)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return nss, cobra.ShellCompDirectiveNoFileComp
+}
+
+// namespaceSeparator separates a konf ID from a namespace in the '<id>/<ns>'
+// shorthand of set. A slash can safely be used, as it can never be part of an
+// ID, see konf.IDFromClusterAndContext
+const namespaceSeparator = "/"
+
+// splitIDAndNamespace splits the '<id>/<ns>' shorthand into its parts. If no
+// namespace is part of the argument, ns will be empty
+func splitIDAndNamespace(arg string) (id konf.KonfID, ns string) {
+	before, after, _ := strings.Cut(arg, namespaceSeparator)
+	return konf.KonfID(before), after
+}
+
 // TODO make a decision where this code should be placed. Currently it does not
 // make a lot of sense to bring it into its own package as it is at the nice
 // intersection between utilizing two packages to fulfil business logic However
@@ -121,6 +311,26 @@ func selectSingleKonf(sm *store.Storemanager, pf prompt.RunFunc) (konf.KonfID, e
 	if err != nil {
 		return "", err
 	}
+
+	if config.Sort() == config.SortFrecency {
+		usage, err := sm.Usage()
+		if err != nil {
+			return "", err
+		}
+		sortByFrecency(k, usage, time.Now())
+	}
+
+	pins, err := sm.Pins()
+	if err != nil {
+		return "", err
+	}
+	k = pinToTop(k, pins)
+
+	// not having an active konf is perfectly fine. In that case there is just nothing to mark
+	if ids, err := currentKonfIDs(sm.Fs); err == nil {
+		markActiveKonfs(k, ids)
+	}
+
 	p := createSetPrompt(k)
 	selPos, err := pf(p)
 	if err != nil {
@@ -135,15 +345,195 @@ func selectSingleKonf(sm *store.Storemanager, pf prompt.RunFunc) (konf.KonfID, e
 	return konf.IDFromClusterAndContext(sel.Cluster, sel.Context), nil
 }
 
-func idOfLatestKonf(sm *store.Storemanager) (konf.KonfID, error) {
-	b, err := afero.ReadFile(sm.Fs, sm.LatestKonfPath)
+// resolveKonfID finds the konf a user meant with query. It tries to interpret
+// query in the following order:
+//  1. the exact ID of a konf
+//  2. an alias of a konf
+//  3. a glob, see store.FetchKonfsForGlob
+//  4. a fuzzy search, see prompt.RankKonfs
+//
+// If a glob or fuzzy search matches multiple konfs, the user can select one of
+// them. When not run interactively, the candidates are returned as an error
+// instead
+func resolveKonfID(sm *store.Storemanager, query string, pf prompt.RunFunc, interactive bool) (konf.KonfID, error) {
+	id := konf.KonfID(query)
+	if _, err := sm.Fs.Stat(sm.StorePathFromID(id)); err == nil {
+		return id, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+
+	id, found, err := sm.IDForAlias(query)
 	if err != nil {
-		if errors.Is(err, fs.ErrNotExist) {
-			return "", fmt.Errorf("could not select latest konf, because no konf was yet set")
+		return "", err
+	}
+	if found {
+		return id, nil
+	}
+
+	var candidates []*store.Metadata
+	if strings.ContainsAny(query, "*?[") {
+		candidates, err = sm.FetchKonfsForGlob(query)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		konfs, err := sm.FetchAllKonfs()
+		if err != nil {
+			return "", err
+		}
+		candidates = prompt.RankKonfs(query, konfs)
+	}
+
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no konf matches %q", query)
+	}
+	if len(candidates) == 1 {
+		return konf.IDFromClusterAndContext(candidates[0].Cluster, candidates[0].Context), nil
+	}
+
+	if !interactive {
+		ids := []string{}
+		for _, c := range candidates {
+			ids = append(ids, string(konf.IDFromClusterAndContext(c.Cluster, c.Context)))
 		}
+		return "", fmt.Errorf("%q matches multiple konfs, please be more specific:\n  %s", query, strings.Join(ids, "\n  "))
+	}
+
+	selPos, err := pf(createSetPrompt(candidates))
+	if err != nil {
 		return "", err
 	}
-	return konf.KonfID(b), nil
+	if selPos >= len(candidates) {
+		return "", fmt.Errorf("invalid selection %d", selPos)
+	}
+	sel := candidates[selPos]
+
+	return konf.IDFromClusterAndContext(sel.Cluster, sel.Context), nil
+}
+
+// confirmProtected makes sure switching to the konf with the supplied id is
+// intended, if the konf is protected. Typing the ID or an alias of the konf in
+// query counts as confirmation. Otherwise the user has to confirm the switch,
+// which is impossible when not run interactively
+func confirmProtected(sm *store.Storemanager, id konf.KonfID, query string, confirm prompt.ConfirmFunc, interactive bool) error {
+	m, err := sm.Meta(id)
+	if err != nil {
+		return err
+	}
+	if !m.Protected || query == string(id) || slices.Contains(m.Aliases, query) {
+		return nil
+	}
+
+	if !interactive {
+		return fmt.Errorf("konf %q is protected. Use its ID or one of its aliases to switch to it", id)
+	}
+	ok, err := confirm(fmt.Sprintf("Konf %s is protected. Switch to it", id))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("switching to protected konf %q has been aborted", id)
+	}
+	return nil
+}
+
+// sortByFrecency sorts konfs by their frecency score, with the highest score
+// first. Konfs with the same score keep their order
+func sortByFrecency(konfs []*store.Metadata, usage map[konf.KonfID]*store.Usage, now time.Time) {
+	score := func(m *store.Metadata) float64 {
+		return usage[konf.IDFromClusterAndContext(m.Cluster, m.Context)].Frecency(now)
+	}
+	sort.SliceStable(konfs, func(i, j int) bool { return score(konfs[i]) > score(konfs[j]) })
+}
+
+// pinToTop marks all pinned konfs and moves them to the top in the order they
+// have been pinned. The order of all other konfs stays untouched
+func pinToTop(konfs []*store.Metadata, pins []konf.KonfID) []*store.Metadata {
+	pinned := make([]*store.Metadata, len(pins))
+	rest := []*store.Metadata{}
+	for _, k := range konfs {
+		i := slices.Index(pins, konf.IDFromClusterAndContext(k.Cluster, k.Context))
+		if i < 0 {
+			rest = append(rest, k)
+			continue
+		}
+		k.Pinned = true
+		pinned[i] = k
+	}
+
+	// pins of konfs that have since been deleted leave a gap
+	out := []*store.Metadata{}
+	for _, k := range pinned {
+		if k != nil {
+			out = append(out, k)
+		}
+	}
+	return append(out, rest...)
+}
+
+// markActiveKonfs marks all konfs whose ID is part of ids as active
+func markActiveKonfs(konfs []*store.Metadata, ids []konf.KonfID) {
+	for _, k := range konfs {
+		k.Active = slices.Contains(ids, konf.IDFromClusterAndContext(k.Cluster, k.Context))
+	}
+}
+
+// idOfLatestKonf returns the n-th most recently used konf. n starts at 1
+func idOfLatestKonf(sm *store.Storemanager, n int) (konf.KonfID, error) {
+	entries, err := recentKonfs(sm)
+	if err != nil {
+		return "", err
+	}
+
+	if len(entries) == 0 {
+		return "", fmt.Errorf("could not select latest konf, because no konf was yet set")
+	}
+	if n < 1 || n > len(entries) {
+		return "", fmt.Errorf("could not select konf %d, because only %d konf(s) are in the history", n, len(entries))
+	}
+
+	return entries[n-1].ID, nil
+}
+
+// recentKonfs returns the history of konf switches, with the most recently
+// used konf first. Each konf is only listed once and konfs that have since
+// been deleted from the store are skipped
+func recentKonfs(sm *store.Storemanager) ([]*store.HistoryEntry, error) {
+	hist, err := sm.History()
+	if err != nil {
+		return nil, err
+	}
+
+	// konf versions before the history was introduced only remember the latest konf
+	if len(hist) == 0 {
+		b, err := afero.ReadFile(sm.Fs, sm.LatestKonfPath)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if err == nil {
+			hist = append(hist, &store.HistoryEntry{ID: konf.KonfID(b)})
+		}
+	}
+
+	entries := []*store.HistoryEntry{}
+	seen := map[konf.KonfID]bool{}
+	for _, e := range hist {
+		if seen[e.ID] {
+			continue
+		}
+		seen[e.ID] = true
+
+		if _, err := sm.Fs.Stat(sm.StorePathFromID(e.ID)); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, nil
 }
 
 func setContext(id konf.KonfID, sm *store.Storemanager) (string, error) {
@@ -152,45 +542,134 @@ func setContext(id konf.KonfID, sm *store.Storemanager) (string, error) {
 		return "", err
 	}
 
-	ppid := os.Getppid()
-	konfID := konf.IDFromProcessID(ppid)
+	konfID := currentSessionID()
 	activeKonf := sm.ActivePathFromID(konfID)
 	err = afero.WriteFile(sm.Fs, activeKonf, k, utils.KonfPerm)
 	if err != nil {
 		return "", err
 	}
+	recordSession(sm, konfID, k)
 
 	return activeKonf, nil
 
 }
 
-func saveLatestKonf(sm *store.Storemanager, id konf.KonfID) error {
-	return afero.WriteFile(sm.Fs, sm.LatestKonfPath, []byte(id), utils.KonfPerm)
+// resolveMergeArgs resolves the args of a merged 'konf set' into konf IDs.
+// Like for a single konf, every arg can use the '<id>/<ns>' shorthand. The
+// namespaces are returned by the ID they belong to. A konf that is supplied
+// multiple times is only merged once
+func resolveMergeArgs(sm *store.Storemanager, args []string, pf prompt.RunFunc, confirm prompt.ConfirmFunc, interactive bool) ([]konf.KonfID, map[konf.KonfID]string, error) {
+	ids := []konf.KonfID{}
+	namespaces := map[konf.KonfID]string{}
+	for _, a := range args {
+		query, ns := splitIDAndNamespace(a)
+		resolved, err := resolveKonfID(sm, string(query), pf, interactive)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := confirmProtected(sm, resolved, string(query), confirm, interactive); err != nil {
+			return nil, nil, err
+		}
+
+		if prev, ok := namespaces[resolved]; ok && ns != "" && prev != ns {
+			return nil, nil, fmt.Errorf("namespace of konf %q has been supplied twice with different values: %q and %q", resolved, prev, ns)
+		}
+		if ns != "" {
+			namespaces[resolved] = ns
+		}
+		// merging a konf twice would result in duplicate clusters, contexts and users
+		if !slices.Contains(ids, resolved) {
+			ids = append(ids, resolved)
+		}
+	}
+	return ids, namespaces, nil
 }
 
-func createSetPrompt(options []*store.Metadata) *promptui.Select {
-	// TODO use ssh/terminal to get the terminalsize and set trunc accordingly https://stackoverflow.com/questions/16569433/get-terminal-size-in-go
-	trunc := 25
-	promptInactive, promptActive, label, fmap := prompt.NewTableOutputTemplates(trunc)
+// resolvePrimary resolves the value of --primary into a konf ID. It has to be
+// one of the ids that are set. Namespaces cannot be supplied through
+// --primary, as they belong to the konfs themselves
+func resolvePrimary(sm *store.Storemanager, primary string, ids []konf.KonfID, pf prompt.RunFunc, interactive bool) (konf.KonfID, error) {
+	if query, ns := splitIDAndNamespace(primary); ns != "" {
+		return "", fmt.Errorf("--primary %q must not contain a namespace, supply it with the konf instead, e.g. '%s/%s'", primary, query, ns)
+	}
+
+	id, err := resolveKonfID(sm, primary, pf, interactive)
+	if err != nil {
+		return "", err
+	}
+	if !slices.Contains(ids, id) {
+		return "", fmt.Errorf("primary konf %q is not one of the konfs to be set", id)
+	}
+	return id, nil
+}
+
+// setMergedContext works like setContext, but merges multiple konfs into a
+// single active kubeconfig. current-context will point to the primary konf.
+// namespaces sets the namespace of individual konfs
+func setMergedContext(ids []konf.KonfID, primary konf.KonfID, namespaces map[konf.KonfID]string, sm *store.Storemanager) (string, error) {
+	konfs := []*konf.Konfig{}
+	for _, id := range ids {
+		k, err := sm.ReadKonfFromStore(id)
+		if err != nil {
+			return "", err
+		}
+		konfs = append(konfs, k)
+	}
+
+	merged, err := konf.MergeKonfigs(konfs, primary)
+	if err != nil {
+		return "", err
+	}
+	// after merging, every context is named after the konf it originates from
+	for i, con := range merged.Contexts {
+		if ns, ok := namespaces[konf.KonfID(con.Name)]; ok {
+			merged.Contexts[i].Context.Namespace = ns
+		}
+	}
+
+	b, err := yaml.Marshal(merged)
+	if err != nil {
+		return "", err
+	}
+
+	konfID := currentSessionID()
+	activeKonf := sm.ActivePathFromID(konfID)
+	err = afero.WriteFile(sm.Fs, activeKonf, b, utils.KonfPerm)
+	if err != nil {
+		return "", err
+	}
+	recordSession(sm, konfID, b)
+
+	return activeKonf, nil
+}
 
-	// Wrapper is required as we need access to options, but the methodSignature from promptUI
-	// requires you to only pass an index not the whole func
-	// This wrapper allows us to unit-test the FuzzyFilterKonf func better
-	var wrapFuzzyFilterKonf = func(input string, index int) bool {
-		return prompt.FuzzyFilterKonf(input, options[index])
+func saveLatestKonf(sm *store.Storemanager, id konf.KonfID) error {
+	// the latest konf file is still written, so downgrading konf does not break 'set -'
+	err := afero.WriteFile(sm.Fs, sm.LatestKonfPath, []byte(id), utils.KonfPerm)
+	if err != nil {
+		return err
 	}
 
+	return sm.AddToHistory(id, time.Now())
+}
+
+func createSetPrompt(options []*store.Metadata) *promptui.Select {
+	cols := config.Columns()
+	widths := prompt.ColumnWidths(cols, options, prompt.TerminalWidth())
+	promptInactive, promptActive, label, fmap := prompt.NewTableOutputTemplates(cols, widths)
+
 	prompt := promptui.Select{
 		Label: label,
 		Items: options,
 		Templates: &promptui.SelectTemplates{
 			Active:   promptActive,
 			Inactive: promptInactive,
+			Details:  prompt.NewDetailsTemplate(),
 			FuncMap:  fmap,
 		},
 		HideSelected: true,
 		Stdout:       os.Stderr,
-		Searcher:     wrapFuzzyFilterKonf,
+		Searcher:     prompt.NewRankedSearcher(options),
 		Size:         15,
 	}
 	return &prompt
@@ -4,8 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
-	"os"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/manifoldco/promptui"
@@ -16,35 +16,88 @@ import (
 	"github.com/simontheleg/konf-go/utils"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8s "k8s.io/client-go/tools/clientcmd/api/v1"
+	"sigs.k8s.io/yaml"
 )
 
 func TestSelectLastKonf(t *testing.T) {
 	storeDir := "./konf/store"
 	activeDir := "./konf/active"
+	stateDir := "./konf/state"
 	latestKonfPath := "./konf/latestkonf" // it is fine to use an imaginary file location here
 	fm := testhelper.FilesystemManager{Storedir: storeDir, Activedir: activeDir, LatestKonfPath: latestKonfPath}
 
+	// history is written oldest first
+	var history = func(ids ...konf.KonfID) func(afero.Fs) {
+		return func(f afero.Fs) {
+			sm := &store.Storemanager{Fs: f, Statedir: stateDir}
+			for i, id := range ids {
+				sm.AddToHistory(id, time.Date(2022, 1, 1, 0, i, 0, 0, time.UTC))
+			}
+		}
+	}
+	var latestKonfInStore = func(f afero.Fs) {
+		skm := testhelper.SampleKonfManager{}
+		afero.WriteFile(f, storeDir+"/context_cluster.yaml", []byte(skm.SingleClusterSingleContextEU()), utils.KonfPerm)
+	}
+
 	tt := map[string]struct {
 		FSCreator func() afero.Fs
+		N         int
 		ExpID     konf.KonfID
 		ExpError  error
 	}{
 		"latestKonf set": {
-			FSCreator: testhelper.FSWithFiles(fm.LatestKonf),
+			FSCreator: testhelper.FSWithFiles(fm.LatestKonf, latestKonfInStore),
+			N:         1,
 			ExpID:     "context_cluster",
 			ExpError:  nil,
 		},
+		"latestKonf set, but konf was deleted": {
+			FSCreator: testhelper.FSWithFiles(fm.LatestKonf),
+			N:         1,
+			ExpID:     "",
+			ExpError:  fmt.Errorf("could not select latest konf, because no konf was yet set"),
+		},
 		"no latestKonf": {
 			FSCreator: testhelper.FSWithFiles(),
+			N:         1,
 			ExpID:     "",
 			ExpError:  fmt.Errorf("could not select latest konf, because no konf was yet set"),
 		},
+		"most recent konf from history": {
+			FSCreator: testhelper.FSWithFiles(fm.SingleClusterSingleContextEU, fm.SingleClusterSingleContextASIA, history("dev-eu_dev-eu-1", "dev-asia_dev-asia-1")),
+			N:         1,
+			ExpID:     "dev-asia_dev-asia-1",
+			ExpError:  nil,
+		},
+		"second most recent konf from history": {
+			FSCreator: testhelper.FSWithFiles(fm.SingleClusterSingleContextEU, fm.SingleClusterSingleContextASIA, history("dev-eu_dev-eu-1", "dev-asia_dev-asia-1", "dev-asia_dev-asia-1")),
+			N:         2,
+			ExpID:     "dev-eu_dev-eu-1",
+			ExpError:  nil,
+		},
+		"deleted konf is skipped": {
+			FSCreator: testhelper.FSWithFiles(fm.SingleClusterSingleContextEU, history("dev-eu_dev-eu-1", "dev-asia_dev-asia-1")),
+			N:         1,
+			ExpID:     "dev-eu_dev-eu-1",
+			ExpError:  nil,
+		},
+		"not enough konfs in history": {
+			FSCreator: testhelper.FSWithFiles(fm.SingleClusterSingleContextEU, history("dev-eu_dev-eu-1")),
+			N:         2,
+			ExpID:     "",
+			ExpError:  fmt.Errorf("could not select konf 2, because only 1 konf(s) are in the history"),
+		},
 	}
 
 	for name, tc := range tt {
 		t.Run(name, func(t *testing.T) {
-			sm := &store.Storemanager{Fs: tc.FSCreator(), Activedir: activeDir, Storedir: storeDir, LatestKonfPath: latestKonfPath}
-			id, err := idOfLatestKonf(sm)
+			sm := &store.Storemanager{Fs: tc.FSCreator(), Activedir: activeDir, Storedir: storeDir, Statedir: stateDir, LatestKonfPath: latestKonfPath}
+			id, err := idOfLatestKonf(sm, tc.N)
 
 			if !testhelper.EqualError(tc.ExpError, err) {
 				t.Errorf("Want error %q, got %q", tc.ExpError, err)
@@ -107,7 +160,7 @@ func TestSaveLatestKonf(t *testing.T) {
 	expID := konf.KonfID("context_cluster")
 
 	f := afero.NewMemMapFs()
-	sm := &store.Storemanager{Fs: f, LatestKonfPath: expFile}
+	sm := &store.Storemanager{Fs: f, Statedir: "./konf/state", LatestKonfPath: expFile}
 	err := saveLatestKonf(sm, expID)
 	if err != nil {
 		t.Errorf("Could not save last konf: %q", err)
@@ -123,12 +176,19 @@ func TestSaveLatestKonf(t *testing.T) {
 	if konf.KonfID(id) != expID {
 		t.Errorf("Exp id to be %q but is %q", expID, id)
 	}
+
+	hist, err := sm.History()
+	if err != nil {
+		t.Errorf("Could not read history: %q", err)
+	}
+	if len(hist) != 1 || hist[0].ID != expID {
+		t.Errorf("Exp history to only contain %q, but got %v", expID, hist)
+	}
 }
 
 func TestSetContext(t *testing.T) {
 	storeDir := "./konf/store"
 	activeDir := "./konf/active"
-	ppid := os.Getppid()
 	skm := testhelper.SampleKonfManager{}
 
 	tt := map[string]struct {
@@ -141,7 +201,7 @@ func TestSetContext(t *testing.T) {
 			"dev-eu_dev-eu",
 			true,
 			nil,
-			activeDir + "/" + string(konf.IDFromProcessID(ppid)) + ".yaml",
+			activeDir + "/" + string(currentSessionID()) + ".yaml",
 		},
 		"invalid id": {
 			"i-am-invalid",
@@ -244,3 +304,490 @@ func TestSelectContext(t *testing.T) {
 		})
 	}
 }
+
+func TestSetMergedContext(t *testing.T) {
+	storeDir := "./konf/store"
+	activeDir := "./konf/active"
+	fm := testhelper.FilesystemManager{Storedir: storeDir, Activedir: activeDir}
+
+	tt := map[string]struct {
+		ids           []konf.KonfID
+		primary       konf.KonfID
+		namespaces    map[konf.KonfID]string
+		expErr        error
+		expKonfPath   string
+		expCurrent    string
+		expNamespaces map[string]string
+	}{
+		"merge two konfs": {
+			[]konf.KonfID{"dev-eu_dev-eu-1", "dev-asia_dev-asia-1"},
+			"dev-asia_dev-asia-1",
+			nil,
+			nil,
+			activeDir + "/" + string(currentSessionID()) + ".yaml",
+			"dev-asia_dev-asia-1",
+			map[string]string{"dev-eu_dev-eu-1": "kube-public", "dev-asia_dev-asia-1": "kube-public"},
+		},
+		"merge with namespaces": {
+			[]konf.KonfID{"dev-eu_dev-eu-1", "dev-asia_dev-asia-1"},
+			"dev-asia_dev-asia-1",
+			map[konf.KonfID]string{"dev-eu_dev-eu-1": "team-a"},
+			nil,
+			activeDir + "/" + string(currentSessionID()) + ".yaml",
+			"dev-asia_dev-asia-1",
+			map[string]string{"dev-eu_dev-eu-1": "team-a", "dev-asia_dev-asia-1": "kube-public"},
+		},
+		"konf does not exist": {
+			[]konf.KonfID{"dev-eu_dev-eu-1", "i-am-invalid"},
+			"dev-eu_dev-eu-1",
+			nil,
+			fs.ErrNotExist,
+			"",
+			"",
+			nil,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			f := testhelper.FSWithFiles(fm.StoreDir, fm.SingleClusterSingleContextEU, fm.SingleClusterSingleContextASIA)()
+			sm := &store.Storemanager{Fs: f, Storedir: storeDir, Activedir: activeDir}
+
+			resKonfPath, err := setMergedContext(tc.ids, tc.primary, tc.namespaces, sm)
+
+			if !errors.Is(err, tc.expErr) {
+				t.Errorf("Want error '%s', got '%s'", tc.expErr, err)
+			}
+
+			if resKonfPath != tc.expKonfPath {
+				t.Errorf("Want konfPath '%s', got '%s'", tc.expKonfPath, resKonfPath)
+			}
+
+			if tc.expKonfPath != "" {
+				b, err := afero.ReadFile(f, tc.expKonfPath)
+				if err != nil {
+					t.Fatalf("Wanted to read file %q, but failed: %q", tc.expKonfPath, err)
+				}
+				var conf k8s.Config
+				if err := yaml.Unmarshal(b, &conf); err != nil {
+					t.Fatalf("Could not unmarshal merged konf: %q", err)
+				}
+				if len(conf.Contexts) != len(tc.ids) {
+					t.Errorf("Exp %d contexts, got %d", len(tc.ids), len(conf.Contexts))
+				}
+				if conf.CurrentContext != tc.expCurrent {
+					t.Errorf("Exp current-context %q, got %q", tc.expCurrent, conf.CurrentContext)
+				}
+				for _, con := range conf.Contexts {
+					if con.Context.Namespace != tc.expNamespaces[con.Name] {
+						t.Errorf("Exp namespace %q for context %q, got %q", tc.expNamespaces[con.Name], con.Name, con.Context.Namespace)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSplitIDAndNamespace(t *testing.T) {
+	tt := map[string]struct {
+		arg   string
+		expID konf.KonfID
+		expNs string
+	}{
+		"id only":            {"dev-eu_dev-eu-1", "dev-eu_dev-eu-1", ""},
+		"id and namespace":   {"dev-eu_dev-eu-1/kube-system", "dev-eu_dev-eu-1", "kube-system"},
+		"trailing separator": {"dev-eu_dev-eu-1/", "dev-eu_dev-eu-1", ""},
+		"latest konf":        {"-/kube-system", "-", "kube-system"},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			id, ns := splitIDAndNamespace(tc.arg)
+			if id != tc.expID {
+				t.Errorf("Exp id %q, got %q", tc.expID, id)
+			}
+			if ns != tc.expNs {
+				t.Errorf("Exp namespace %q, got %q", tc.expNs, ns)
+			}
+		})
+	}
+}
+
+func TestCompleteSetNamespace(t *testing.T) {
+	storeDir := "./konf/store"
+	activeDir := "./konf/active"
+	fm := testhelper.FilesystemManager{Storedir: storeDir, Activedir: activeDir}
+	nss := []runtime.Object{testhelper.NamespaceFromName("kube-system"), testhelper.NamespaceFromName("public")}
+
+	sm := &store.Storemanager{Activedir: activeDir, Storedir: storeDir, Fs: testhelper.FSWithFiles(fm.StoreDir, fm.SingleClusterSingleContextEU)()}
+	scmd := newSetCommand()
+	scmd.sm = sm
+	scmd.clientSetFromFile = func(f afero.Fs, path string) (kubernetes.Interface, error) {
+		if path != sm.StorePathFromID("dev-eu_dev-eu-1") {
+			return nil, fmt.Errorf("unexpected path %q", path)
+		}
+		return fake.NewSimpleClientset(nss...), nil
+	}
+
+	expComp := []string{"dev-eu_dev-eu-1/kube-system", "dev-eu_dev-eu-1/public"}
+	res, compdirec := scmd.completeSet(scmd.cmd, []string{}, "dev-eu_dev-eu-1/")
+
+	if !cmp.Equal(res, expComp) {
+		t.Errorf("Exp and given comps differ: \n '%s'", cmp.Diff(expComp, res))
+	}
+
+	if compdirec != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("Exp compdirec %q, got %q", cobra.ShellCompDirectiveNoFileComp, compdirec)
+	}
+}
+
+func TestSortByFrecency(t *testing.T) {
+	now := time.Date(2022, 1, 10, 12, 0, 0, 0, time.UTC)
+	konfs := []*store.Metadata{
+		{Context: "a", Cluster: "a"},
+		{Context: "b", Cluster: "b"},
+		{Context: "c", Cluster: "c"},
+		{Context: "d", Cluster: "d"},
+	}
+	usage := map[konf.KonfID]*store.Usage{
+		// used more often, but a long time ago
+		"b_b": {Count: 10, LastUsed: now.Add(-30 * 24 * time.Hour)},
+		"c_c": {Count: 1, LastUsed: now.Add(-5 * time.Minute)},
+		"d_d": {Count: 3, LastUsed: now.Add(-2 * time.Hour)},
+	}
+
+	sortByFrecency(konfs, usage, now)
+
+	res := []string{}
+	for _, k := range konfs {
+		res = append(res, k.Context)
+	}
+	exp := []string{"d", "c", "b", "a"}
+	if !cmp.Equal(res, exp) {
+		t.Errorf("Exp order %v, got %v", exp, res)
+	}
+}
+
+func TestMarkActiveKonfs(t *testing.T) {
+	konfs := []*store.Metadata{
+		{Context: "dev-eu", Cluster: "dev-eu-1"},
+		{Context: "dev-asia", Cluster: "dev-asia-1", Active: true},
+		{Context: "dev-us", Cluster: "dev-us-1"},
+	}
+
+	markActiveKonfs(konfs, []konf.KonfID{"dev-eu_dev-eu-1", "dev-us_dev-us-1"})
+
+	exp := []bool{true, false, true}
+	for i, k := range konfs {
+		if k.Active != exp[i] {
+			t.Errorf("Exp konf %q to have active %t, got %t", k.Context, exp[i], k.Active)
+		}
+	}
+}
+
+func TestPinToTop(t *testing.T) {
+	konfs := []*store.Metadata{
+		{Context: "a", Cluster: "a"},
+		{Context: "b", Cluster: "b"},
+		{Context: "c", Cluster: "c"},
+		{Context: "d", Cluster: "d"},
+	}
+
+	res := pinToTop(konfs, []konf.KonfID{"d_d", "deleted_deleted", "b_b"})
+
+	order := []string{}
+	pinned := []bool{}
+	for _, k := range res {
+		order = append(order, k.Context)
+		pinned = append(pinned, k.Pinned)
+	}
+	if exp := []string{"d", "b", "a", "c"}; !cmp.Equal(order, exp) {
+		t.Errorf("Exp order %v, got %v", exp, order)
+	}
+	if exp := []bool{true, true, false, false}; !cmp.Equal(pinned, exp) {
+		t.Errorf("Exp pinned %v, got %v", exp, pinned)
+	}
+}
+
+func TestResolveMergeArgs(t *testing.T) {
+	storeDir := "./konf/store"
+	fm := testhelper.FilesystemManager{Storedir: storeDir}
+	f := testhelper.FSWithFiles(fm.StoreDir, fm.SingleClusterSingleContextEU, fm.SingleClusterSingleContextASIA)()
+	sm := &store.Storemanager{Fs: f, Storedir: storeDir, Statedir: "./konf/state"}
+	sm.SetMeta("dev-asia_dev-asia-1", &store.KonfMeta{Aliases: []string{"asia"}})
+
+	tt := map[string]struct {
+		args          []string
+		expIDs        []konf.KonfID
+		expNamespaces map[konf.KonfID]string
+		expErr        error
+	}{
+		"plain ids": {
+			[]string{"dev-eu_dev-eu-1", "dev-asia_dev-asia-1"},
+			[]konf.KonfID{"dev-eu_dev-eu-1", "dev-asia_dev-asia-1"},
+			map[konf.KonfID]string{},
+			nil,
+		},
+		"namespace shorthand": {
+			[]string{"dev-eu_dev-eu-1/team-a", "asia/team-b"},
+			[]konf.KonfID{"dev-eu_dev-eu-1", "dev-asia_dev-asia-1"},
+			map[konf.KonfID]string{"dev-eu_dev-eu-1": "team-a", "dev-asia_dev-asia-1": "team-b"},
+			nil,
+		},
+		"konf supplied twice": {
+			[]string{"dev-asia_dev-asia-1", "dev-eu_dev-eu-1", "asia/team-b"},
+			[]konf.KonfID{"dev-asia_dev-asia-1", "dev-eu_dev-eu-1"},
+			map[konf.KonfID]string{"dev-asia_dev-asia-1": "team-b"},
+			nil,
+		},
+		"konf supplied twice with different namespaces": {
+			[]string{"asia/team-a", "dev-asia_dev-asia-1/team-b"},
+			nil,
+			nil,
+			fmt.Errorf("namespace of konf %q has been supplied twice with different values: %q and %q", "dev-asia_dev-asia-1", "team-a", "team-b"),
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			ids, namespaces, err := resolveMergeArgs(sm, tc.args, nil, nil, false)
+			if !testhelper.EqualError(tc.expErr, err) {
+				t.Errorf("Exp err %q, got %q", tc.expErr, err)
+			}
+			if !cmp.Equal(tc.expIDs, ids) {
+				t.Errorf("Exp ids %v, got %v", tc.expIDs, ids)
+			}
+			if !cmp.Equal(tc.expNamespaces, namespaces) {
+				t.Errorf("Exp namespaces %v, got %v", tc.expNamespaces, namespaces)
+			}
+		})
+	}
+}
+
+func TestSetMultipleKonfs(t *testing.T) {
+	tt := map[string]struct {
+		args    []string
+		primary string
+		// expIDs are the konfs in the active kubeconfig, as reported by konf.IDsFromKubeconfig
+		expIDs []konf.KonfID
+		expNs  string
+		expErr error
+	}{
+		"merged konfs": {
+			args:    []string{"dev-eu_dev-eu-1", "asia/team-b"},
+			primary: "asia",
+			expIDs:  []konf.KonfID{"dev-asia_dev-asia-1", "dev-eu_dev-eu-1"},
+			expNs:   "team-b",
+		},
+		"same konf twice": {
+			args:   []string{"dev-eu_dev-eu-1", "dev-eu_dev-eu-1"},
+			expIDs: []konf.KonfID{"dev-eu_dev-eu-1"},
+			expNs:  "kube-public",
+		},
+		"same konf twice with namespace": {
+			args:   []string{"dev-eu_dev-eu-1", "dev-eu_dev-eu-1/team-a"},
+			expIDs: []konf.KonfID{"dev-eu_dev-eu-1"},
+			expNs:  "team-a",
+		},
+		"alias and id of the same konf": {
+			args:    []string{"asia", "dev-asia_dev-asia-1"},
+			primary: "asia",
+			expIDs:  []konf.KonfID{"dev-asia_dev-asia-1"},
+			expNs:   "kube-public",
+		},
+		"primary with namespace": {
+			args:    []string{"dev-eu_dev-eu-1", "asia"},
+			primary: "asia/team-b",
+			expErr:  fmt.Errorf("--primary %q must not contain a namespace, supply it with the konf instead, e.g. '%s/%s'", "asia/team-b", "asia", "team-b"),
+		},
+		"primary not among konfs": {
+			args:    []string{"dev-eu_dev-eu-1", "dev-eu_dev-eu-1"},
+			primary: "asia",
+			expErr:  fmt.Errorf("primary konf %q is not one of the konfs to be set", "dev-asia_dev-asia-1"),
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(sessionEnv, "shell_1")
+			fm := testhelper.FilesystemManager{Storedir: "./konf/store", Activedir: "./konf/active"}
+			f := testhelper.FSWithFiles(fm.StoreDir, fm.ActiveDir, fm.SingleClusterSingleContextEU, fm.SingleClusterSingleContextASIA)()
+			sm := &store.Storemanager{Fs: f, Storedir: "./konf/store", Activedir: "./konf/active", Statedir: "./konf/state", LatestKonfPath: "./konf/latestkonf"}
+			sm.SetMeta("dev-asia_dev-asia-1", &store.KonfMeta{Aliases: []string{"asia"}})
+
+			sc := &setCmd{
+				sm:           sm,
+				confirm:      func(string) (bool, error) { return true, nil },
+				isTerminal:   func() bool { return false },
+				startCleanup: func() error { return nil },
+				primary:      tc.primary,
+			}
+
+			err := sc.set(sc.cmd, tc.args)
+			if !testhelper.EqualError(tc.expErr, err) {
+				t.Fatalf("Exp err %q, got %q", tc.expErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			b, err := afero.ReadFile(f, sm.ActivePathFromID("shell_1"))
+			if err != nil {
+				t.Fatalf("Could not read active konf: %v", err)
+			}
+			var conf k8s.Config
+			if err := yaml.Unmarshal(b, &conf); err != nil {
+				t.Fatalf("Could not parse active konf: %v", err)
+			}
+			if ids := konf.IDsFromKubeconfig(&conf); !cmp.Equal(tc.expIDs, ids) {
+				t.Errorf("Exp konfs %v, got %v", tc.expIDs, ids)
+			}
+			con, err := konf.CurrentContext(&conf)
+			if err != nil {
+				t.Fatalf("Exp current context to be set, got %v", err)
+			}
+			if con.Context.Namespace != tc.expNs {
+				t.Errorf("Exp namespace %q, got %q", tc.expNs, con.Context.Namespace)
+			}
+		})
+	}
+}
+
+func TestResolveKonfID(t *testing.T) {
+	storeDir := "./konf/store"
+	activeDir := "./konf/active"
+	stateDir := "./konf/state"
+	fm := testhelper.FilesystemManager{Storedir: storeDir, Activedir: activeDir}
+	f := testhelper.FSWithFiles(fm.StoreDir, fm.SingleClusterSingleContextEU, fm.SingleClusterSingleContextEU2, fm.SingleClusterSingleContextASIA)()
+	sm := &store.Storemanager{Fs: f, Activedir: activeDir, Storedir: storeDir, Statedir: stateDir}
+	sm.SetMeta("dev-asia_dev-asia-1", &store.KonfMeta{Aliases: []string{"asia"}})
+
+	var promptItems []*store.Metadata
+	selectSecond := func(s *promptui.Select) (int, error) {
+		promptItems = s.Items.([]*store.Metadata)
+		return 1, nil
+	}
+
+	tt := map[string]struct {
+		query           string
+		interactive     bool
+		expID           konf.KonfID
+		expErr          error
+		expPromptLength int
+	}{
+		"exact id": {
+			"dev-eu_dev-eu-1",
+			false,
+			"dev-eu_dev-eu-1",
+			nil,
+			0,
+		},
+		"alias": {
+			"asia",
+			false,
+			"dev-asia_dev-asia-1",
+			nil,
+			0,
+		},
+		"glob with single match": {
+			"*asia*",
+			false,
+			"dev-asia_dev-asia-1",
+			nil,
+			0,
+		},
+		"unique fuzzy match": {
+			"asia-1",
+			false,
+			"dev-asia_dev-asia-1",
+			nil,
+			0,
+		},
+		"multiple matches in terminal": {
+			"dev-eu*",
+			true,
+			"dev-eu_dev-eu-2",
+			nil,
+			2,
+		},
+		"multiple matches without terminal": {
+			"eu",
+			false,
+			"",
+			fmt.Errorf("%q matches multiple konfs, please be more specific:\n  %s\n  %s", "eu", "dev-eu_dev-eu-1", "dev-eu_dev-eu-2"),
+			0,
+		},
+		"no match": {
+			"oranges",
+			false,
+			"",
+			fmt.Errorf("no konf matches %q", "oranges"),
+			0,
+		},
+		"match in store path only": {
+			"store",
+			false,
+			"",
+			fmt.Errorf("no konf matches %q", "store"),
+			0,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			promptItems = nil
+			id, err := resolveKonfID(sm, tc.query, selectSecond, tc.interactive)
+
+			if !testhelper.EqualError(err, tc.expErr) {
+				t.Errorf("Exp err %q, got %q", tc.expErr, err)
+			}
+
+			if id != tc.expID {
+				t.Errorf("Exp id %q, got %q", tc.expID, id)
+			}
+
+			if len(promptItems) != tc.expPromptLength {
+				t.Errorf("Exp prompt with %d items, got %d", tc.expPromptLength, len(promptItems))
+			}
+		})
+	}
+}
+
+func TestConfirmProtected(t *testing.T) {
+	prod := konf.KonfID("prod_prod-1")
+	sm := &store.Storemanager{Fs: afero.NewMemMapFs(), Statedir: "./konf/state"}
+	sm.SetMeta(prod, &store.KonfMeta{Aliases: []string{"prod"}, Protected: true})
+
+	tt := map[string]struct {
+		id          konf.KonfID
+		query       string
+		interactive bool
+		answer      bool
+		expConfirm  bool
+		expErr      error
+	}{
+		"konf is not protected":  {"dev_dev-1", "", true, false, false, nil},
+		"id has been typed":      {prod, string(prod), false, false, false, nil},
+		"alias has been typed":   {prod, "prod", false, false, false, nil},
+		"selected and confirmed": {prod, "", true, true, true, nil},
+		"selected and aborted":   {prod, "", true, false, true, fmt.Errorf("switching to protected konf %q has been aborted", prod)},
+		"fuzzy match":            {prod, "pro", true, true, true, nil},
+		"not interactive": {prod, "-", false, true, false,
+			fmt.Errorf("konf %q is protected. Use its ID or one of its aliases to switch to it", prod)},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			confirmed := false
+			confirm := func(string) (bool, error) { confirmed = true; return tc.answer, nil }
+
+			err := confirmProtected(sm, tc.id, tc.query, confirm, tc.interactive)
+			if !testhelper.EqualError(tc.expErr, err) {
+				t.Errorf("Exp err %q, got %q", tc.expErr, err)
+			}
+			if confirmed != tc.expConfirm {
+				t.Errorf("Exp confirmation to be asked: %t, got %t", tc.expConfirm, confirmed)
+			}
+		})
+	}
+}
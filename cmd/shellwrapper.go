@@ -7,6 +7,8 @@ import (
 )
 
 type shellwrapperCmd struct {
+	checkExpiry bool
+
 	cmd *cobra.Command
 }
 
@@ -20,18 +22,39 @@ func newShellwrapperCmd() *shellwrapperCmd {
 
 The output of this command should be sourced in your .rc file.
 
+With --check-expiry, the wrapper additionally checks before every prompt whether the konf of the shell has expired.
+This runs konf before every prompt, so it is only worth it if konfs with a ttl are used. Without it, expired konfs
+are only revoked by 'konf cleanup'.
+
 See https://github.com/SimonTheLeg/konf-go#installation on how to do so
 `,
 		RunE: sc.shellwrapper,
 		Args: cobra.ExactArgs(1),
 	}
 
+	sc.cmd.Flags().BoolVar(&sc.checkExpiry, "check-expiry", false, "check before every prompt whether the konf of the shell has expired (default is false)")
+
 	return &sc
 }
 
 func (c *shellwrapperCmd) shellwrapper(cmd *cobra.Command, args []string) error {
-	var wrapper string
+	wrapper, err := wrapperFor(args[0], c.checkExpiry)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(wrapper)
+
+	return nil
+}
+
+// wrapperFor returns the shellwrapper for the supplied shell. If checkExpiry
+// is true, it includes a hook that checks for an expired konf before every
+// prompt
+func wrapperFor(shell string, checkExpiry bool) (string, error) {
 	var zsh = `
+# every shell gets its own session, so konf can tell it apart from the shells it has been started from
+export KONF_SESSION="$$.$RANDOM$RANDOM"
 konf() {
   res=$(konf-go $@)
   # only change $KUBECONFIG if instructed by konf-go
@@ -39,6 +62,8 @@ konf() {
   then
     # this basically takes the line and cuts out the KUBECONFIGCHANGE Part
     export KUBECONFIG="${res#*KUBECONFIGCHANGE:}"
+    # a new konf has to be checked for expiry again, see konf_check
+    unset _konf_revoked
   else
     # this makes --help work
     echo "${res}"
@@ -48,9 +73,27 @@ konf_cleanup() {
   konf-go cleanup
 }
 add-zsh-hook zshexit konf_cleanup
+`
+
+	var zshCheck = `
+# warn before every prompt once the konf of this shell has expired. After the warning, konf is not run again until another konf is set
+konf_check() {
+  local ret=$?
+  if [[ -n $KUBECONFIG && $KUBECONFIG != $_konf_revoked ]]
+  then
+    if [[ $(konf-go sessions check) == "KUBECONFIGREVOKED" ]]
+    then
+      _konf_revoked=$KUBECONFIG
+    fi
+  fi
+  return $ret
+}
+add-zsh-hook precmd konf_check
 `
 
 	var bash = `
+# every shell gets its own session, so konf can tell it apart from the shells it has been started from
+export KONF_SESSION="$$.$RANDOM$RANDOM"
 konf() {
   res=$(konf-go $@)
   # only change $KUBECONFIG if instructed by konf-go
@@ -58,6 +101,8 @@ konf() {
   then
     # this basically takes the line and cuts out the KUBECONFIGCHANGE Part
     export KUBECONFIG="${res#*KUBECONFIGCHANGE:}"
+    # a new konf has to be checked for expiry again, see konf_check
+    unset _konf_revoked
   else
     # this makes --help work
     echo "${res}"
@@ -68,15 +113,38 @@ konf_cleanup() {
 }
 
 trap konf_cleanup EXIT
+`
+
+	var bashCheck = `
+# warn before every prompt once the konf of this shell has expired. After the warning, konf is not run again until another konf is set
+konf_check() {
+  local ret=$?
+  if [[ -n $KUBECONFIG && $KUBECONFIG != "$_konf_revoked" ]]
+  then
+    if [[ $(konf-go sessions check) == "KUBECONFIGREVOKED" ]]
+    then
+      _konf_revoked=$KUBECONFIG
+    fi
+  fi
+  return $ret
+}
+if [[ $PROMPT_COMMAND != *konf_check* ]]
+then
+  PROMPT_COMMAND="konf_check${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
 `
 
 	var fish = `
+# every shell gets its own session, so konf can tell it apart from the shells it has been started from
+set -gx KONF_SESSION "$fish_pid."(random)(random)
 function konf -w konf-go
     set -f res (konf-go $argv)
     # only change $KUBECONFIG if instructed by konf-go
     if string match -q 'KUBECONFIGCHANGE:*' $res
         # this basically takes the line and cuts out the KUBECONFIGCHANGE Part
         set -gx KUBECONFIG (string replace -r '^KUBECONFIGCHANGE:' '' $res)
+        # a new konf has to be checked for expiry again, see konf_check
+        set -e _konf_revoked
     else
         # this makes --help work
         # because fish does not support bracketed vars, we use printf instead
@@ -91,18 +159,32 @@ end
 trap konf_cleanup EXIT
 `
 
-	switch args[0] {
+	var fishCheck = `
+# warn before every prompt once the konf of this shell has expired. After the warning, konf is not run again until another konf is set
+function konf_check --on-event fish_prompt
+    if test -n "$KUBECONFIG" -a "$KUBECONFIG" != "$_konf_revoked"
+        set -l res (konf-go sessions check)
+        if test "$res" = KUBECONFIGREVOKED
+            set -g _konf_revoked $KUBECONFIG
+        end
+    end
+end
+`
+
+	var wrapper, check string
+	switch shell {
 	case "zsh":
-		wrapper = zsh
+		wrapper, check = zsh, zshCheck
 	case "bash":
-		wrapper = bash
+		wrapper, check = bash, bashCheck
 	case "fish":
-		wrapper = fish
+		wrapper, check = fish, fishCheck
 	default:
-		return fmt.Errorf("konf currently does not support %s", args[0])
+		return "", fmt.Errorf("konf currently does not support %s", shell)
 	}
 
-	fmt.Println(wrapper)
-
-	return nil
+	if checkExpiry {
+		wrapper += check
+	}
+	return wrapper, nil
 }
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/simontheleg/konf-go/testhelper"
@@ -44,3 +45,25 @@ func TestShellWrapperCmd(t *testing.T) {
 		})
 	}
 }
+
+func TestWrapperForCheckExpiry(t *testing.T) {
+	for _, shell := range []string{"zsh", "bash", "fish"} {
+		for _, checkExpiry := range []bool{true, false} {
+			t.Run(fmt.Sprintf("%s check expiry %t", shell, checkExpiry), func(t *testing.T) {
+				wrapper, err := wrapperFor(shell, checkExpiry)
+				if err != nil {
+					t.Fatalf("Exp no error, got %v", err)
+				}
+
+				// without the flag, konf must not be run before every prompt
+				if hooked := strings.Contains(wrapper, "konf-go sessions check"); hooked != checkExpiry {
+					t.Errorf("Exp expiry check to be included: %t, got %t", checkExpiry, hooked)
+				}
+				// the check has to stop warning after the first warning and start again once another konf is set
+				if checkExpiry && (!strings.Contains(wrapper, "KUBECONFIGREVOKED") || !strings.Contains(wrapper, "_konf_revoked")) {
+					t.Errorf("Exp expiry check to only warn once, got wrapper:\n%s", wrapper)
+				}
+			})
+		}
+	}
+}
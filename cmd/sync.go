@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/simontheleg/konf-go/config"
+	"github.com/simontheleg/konf-go/konf"
+	log "github.com/simontheleg/konf-go/log"
+	"github.com/simontheleg/konf-go/store"
+	"github.com/simontheleg/konf-go/utils"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	k8s "k8s.io/client-go/tools/clientcmd/api/v1"
+	"sigs.k8s.io/yaml"
+)
+
+type syncCmd struct {
+	sm      *store.Storemanager
+	isAlive func(konf.KonfID) (bool, error)
+
+	allSessions bool
+
+	cmd *cobra.Command
+}
+
+func newSyncCmd() *syncCmd {
+	fs := afero.NewOsFs()
+	sm := &store.Storemanager{Fs: fs, Activedir: config.ActiveDir(), Storedir: config.StoreDir(), Statedir: config.StateDir()}
+	sc := &syncCmd{
+		sm:      sm,
+		isAlive: sessionIsAlive,
+	}
+
+	sc.cmd = &cobra.Command{
+		Use:   "sync",
+		Short: "Refresh active kubeconfigs from the store",
+		Long: `Copy the konfs in the store to the active configs (stored in konfDir/active) that have been created from them.
+This is useful after a konf in the store has been edited or imported again, as shells that already use the konf keep their old copy otherwise.
+The namespace of every session is kept.
+
+Examples:
+-> 'sync' refresh the active config of the current shell
+-> 'sync --all-sessions' refresh the active configs of all open shells
+`,
+		RunE: sc.sync,
+		Args: cobra.NoArgs,
+	}
+
+	sc.cmd.Flags().BoolVar(&sc.allSessions, "all-sessions", false, "refresh the active configs of all open shells instead of only the current one (default is false)")
+
+	return sc
+}
+
+func (c *syncCmd) sync(cmd *cobra.Command, args []string) error {
+	var ids []konf.KonfID
+	// every active konf is refreshed in place, including the ones that nested shells have inherited, as
+	// syncing keeps the namespaces and therefore only changes what the store would have set anyway
+	if c.allSessions {
+		sessions, err := liveSessions(c.sm, c.isAlive)
+		if err != nil {
+			return err
+		}
+		for _, s := range sessions {
+			ids = append(ids, s.ID)
+		}
+	} else {
+		kPath, err := kubeconfigEnv()
+		if err != nil {
+			return err
+		}
+		if _, ok := sessionFromKubeconfig(c.sm, kPath); !ok {
+			return fmt.Errorf("KUBECONFIG %q is not managed by konf, so it cannot be refreshed from the store", kPath)
+		}
+
+		// the shell the kubeconfig has been inherited from keeps its copy, see forkSession
+		kPath, forked, err := forkSession(c.sm, kPath, currentSessionID())
+		if err != nil {
+			return err
+		}
+		if forked {
+			log.Info("Copied the konf inherited from another shell, so only the konf of this shell is refreshed")
+			changeKubeconfig(kPath)
+		}
+		id, _ := sessionFromKubeconfig(c.sm, kPath)
+		ids = []konf.KonfID{id}
+	}
+
+	report := syncSessions(c.sm, ids)
+	for _, id := range report.Updated {
+		log.Info("Updated konf of session %s", id)
+	}
+	log.Info("%s", report.summary())
+
+	if len(report.Failed) > 0 {
+		return fmt.Errorf("could not sync %d session(s)", len(report.Failed))
+	}
+	return nil
+}
+
+// syncReport summarizes what syncSessions did with every session
+type syncReport struct {
+	Updated []konf.KonfID
+	// UpToDate are the sessions whose active konf already matched the store
+	UpToDate []konf.KonfID
+	Failed   map[konf.KonfID]error
+}
+
+// summary returns a single line describing the report
+func (r *syncReport) summary() string {
+	return fmt.Sprintf("Updated %d session(s), %d already up to date, %d failed", len(r.Updated), len(r.UpToDate), len(r.Failed))
+}
+
+// syncSessions refreshes the active konfs of the sessions with the supplied
+// ids from the store. A session that cannot be refreshed (e.g. because its
+// konf has been deleted from the store) does not stop the others. Instead it
+// is recorded as failed in the returned report
+func syncSessions(sm *store.Storemanager, ids []konf.KonfID) *syncReport {
+	report := &syncReport{Failed: map[konf.KonfID]error{}}
+	for _, id := range ids {
+		updated, err := syncSession(sm, id)
+		switch {
+		case err != nil:
+			log.Warn("Could not sync session %s: %v", id, err)
+			report.Failed[id] = err
+		case updated:
+			report.Updated = append(report.Updated, id)
+		default:
+			report.UpToDate = append(report.UpToDate, id)
+		}
+	}
+	return report
+}
+
+// syncSession copies the konfs of the session with the supplied id from the
+// store to its active konf again. Merged sessions are merged again with the
+// same primary konf. The namespace of every context is kept, as it is local to
+// the session. It returns true if the active konf has been changed
+func syncSession(sm *store.Storemanager, id konf.KonfID) (bool, error) {
+	aPath := sm.ActivePathFromID(id)
+	b, err := afero.ReadFile(sm.Fs, aPath)
+	if err != nil {
+		return false, err
+	}
+	var active k8s.Config
+	if err := yaml.Unmarshal(b, &active); err != nil {
+		return false, err
+	}
+
+	fresh, err := storeKonfOf(sm, &active)
+	if err != nil {
+		return false, err
+	}
+
+	namespaces := map[string]string{}
+	for _, con := range active.Contexts {
+		namespaces[con.Name] = con.Context.Namespace
+	}
+	for i := range fresh.Contexts {
+		if ns, ok := namespaces[fresh.Contexts[i].Name]; ok {
+			fresh.Contexts[i].Context.Namespace = ns
+		}
+	}
+
+	// the active konf is compared in its marshalled form, so formatting differences are not counted as changes
+	old, err := yaml.Marshal(active)
+	if err != nil {
+		return false, err
+	}
+	refreshed, err := yaml.Marshal(fresh)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(old, refreshed) {
+		return false, nil
+	}
+
+	if err := utils.WriteFileAtomic(sm.Fs, aPath, refreshed, utils.KonfPerm); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// storeKonfOf builds the kubeconfig 'konf set' would create from the store
+// for the konfs in the active kubeconfig. Merged konfs are merged again in
+// their original order and with the same primary konf
+func storeKonfOf(sm *store.Storemanager, active *k8s.Config) (*k8s.Config, error) {
+	ids := konf.IDsFromKubeconfig(active)
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("active konf does not contain any context")
+	}
+	primary := ids[0]
+	// the order has to be kept, so unchanged merged konfs do not differ from the store
+	if len(active.Contexts) > 1 {
+		ids = []konf.KonfID{}
+		for _, con := range active.Contexts {
+			ids = append(ids, konf.KonfID(con.Name))
+		}
+	}
+
+	konfs := []*konf.Konfig{}
+	for _, id := range ids {
+		k, err := sm.ReadKonfFromStore(id)
+		if err != nil {
+			return nil, fmt.Errorf("could not read konf %q from the store: %v", id, err)
+		}
+		konfs = append(konfs, k)
+	}
+
+	if len(konfs) == 1 {
+		return &konfs[0].Kubeconfig, nil
+	}
+
+	return konf.MergeKonfigs(konfs, primary)
+}
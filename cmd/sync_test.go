@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/store"
+	"github.com/simontheleg/konf-go/testhelper"
+	"github.com/simontheleg/konf-go/utils"
+	"github.com/spf13/afero"
+	k8s "k8s.io/client-go/tools/clientcmd/api/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func TestSyncSessions(t *testing.T) {
+	var skm testhelper.SampleKonfManager
+	sm := &store.Storemanager{Fs: afero.NewMemMapFs(), Activedir: "./konf/active", Storedir: "./konf/store", Statedir: "./konf/state"}
+
+	eu := skm.SingleClusterSingleContextEU()
+	asia := skm.SingleClusterSingleContextASIA()
+	euEdited := strings.ReplaceAll(eu, "https://10.1.1.0", "https://10.1.1.1")
+
+	var merged []byte
+	{
+		var konfs []*konf.Konfig
+		for _, k := range []struct {
+			id   konf.KonfID
+			conf string
+		}{{"dev-eu_dev-eu-1", eu}, {"dev-asia_dev-asia-1", asia}} {
+			kk := &konf.Konfig{Id: k.id}
+			if err := yaml.Unmarshal([]byte(k.conf), &kk.Kubeconfig); err != nil {
+				t.Fatalf("Could not parse konf, please check test code: %v", err)
+			}
+			konfs = append(konfs, kk)
+		}
+		conf, err := konf.MergeKonfigs(konfs, "dev-asia_dev-asia-1")
+		if err != nil {
+			t.Fatalf("Could not merge konfs, please check test code: %v", err)
+		}
+		for i := range conf.Contexts {
+			conf.Contexts[i].Context.Namespace = "team-" + conf.Contexts[i].Name
+		}
+		if merged, err = yaml.Marshal(conf); err != nil {
+			t.Fatalf("Could not marshal konf, please check test code: %v", err)
+		}
+	}
+
+	files := map[string]string{
+		sm.StorePathFromID("dev-eu_dev-eu-1"):     euEdited,
+		sm.StorePathFromID("dev-asia_dev-asia-1"): asia,
+		// the namespace has been changed in this session
+		sm.ActivePathFromID("100"): strings.ReplaceAll(eu, "kube-public", "team-a"),
+		sm.ActivePathFromID("200"): asia,
+		// the konf of this session has been deleted from the store
+		sm.ActivePathFromID("300"): strings.ReplaceAll(eu, "dev-eu-1", "dev-eu-2"),
+		sm.ActivePathFromID("400"): string(merged),
+	}
+	for path, content := range files {
+		if err := afero.WriteFile(sm.Fs, path, []byte(content), utils.KonfPerm); err != nil {
+			t.Fatalf("Could not create file, please check test code: %v", err)
+		}
+	}
+
+	report := syncSessions(sm, []konf.KonfID{"100", "200", "300", "400"})
+
+	if exp := []konf.KonfID{"100", "400"}; !cmp.Equal(exp, report.Updated) {
+		t.Errorf("Exp updated sessions %v, got %v", exp, report.Updated)
+	}
+	if exp := []konf.KonfID{"200"}; !cmp.Equal(exp, report.UpToDate) {
+		t.Errorf("Exp up to date sessions %v, got %v", exp, report.UpToDate)
+	}
+	if _, ok := report.Failed["300"]; !ok || len(report.Failed) != 1 {
+		t.Errorf("Exp only session 300 to fail, got %v", report.Failed)
+	}
+
+	type expContext struct {
+		server, namespace string
+	}
+	expActive := map[konf.KonfID]struct {
+		current  string
+		contexts map[string]expContext
+	}{
+		"100": {"dev-eu", map[string]expContext{"dev-eu": {"https://10.1.1.1", "team-a"}}},
+		"400": {"dev-asia_dev-asia-1", map[string]expContext{
+			"dev-eu_dev-eu-1":     {"https://10.1.1.1", "team-dev-eu_dev-eu-1"},
+			"dev-asia_dev-asia-1": {"https://10.1.1.0", "team-dev-asia_dev-asia-1"},
+		}},
+	}
+	for id, exp := range expActive {
+		b, err := afero.ReadFile(sm.Fs, sm.ActivePathFromID(id))
+		if err != nil {
+			t.Fatalf("Could not read active konf %s: %v", id, err)
+		}
+		var conf k8s.Config
+		if err := yaml.Unmarshal(b, &conf); err != nil {
+			t.Fatalf("Could not parse active konf %s: %v", id, err)
+		}
+
+		if conf.CurrentContext != exp.current {
+			t.Errorf("Exp current-context of session %s to be %q, got %q", id, exp.current, conf.CurrentContext)
+		}
+		for _, con := range conf.Contexts {
+			var server string
+			for _, cl := range conf.Clusters {
+				if cl.Name == con.Context.Cluster {
+					server = cl.Cluster.Server
+				}
+			}
+			if got := (expContext{server, con.Context.Namespace}); got != exp.contexts[con.Name] {
+				t.Errorf("Exp context %q of session %s to be %v, got %v", con.Name, id, exp.contexts[con.Name], got)
+			}
+		}
+	}
+
+	// syncing again must not change anything
+	report = syncSessions(sm, []konf.KonfID{"100", "200", "400"})
+	if len(report.Updated) != 0 {
+		t.Errorf("Exp no updated sessions when syncing twice, got %v", report.Updated)
+	}
+}
+
+func TestSyncInheritedSession(t *testing.T) {
+	var skm testhelper.SampleKonfManager
+	sm := &store.Storemanager{Fs: afero.NewMemMapFs(), Activedir: "./konf/active", Storedir: "./konf/store", Statedir: "./konf/state"}
+
+	eu := strings.ReplaceAll(skm.SingleClusterSingleContextEU(), "kube-public", "team-a")
+	files := map[string]string{
+		sm.StorePathFromID("dev-eu_dev-eu-1"): strings.ReplaceAll(skm.SingleClusterSingleContextEU(), "https://10.1.1.0", "https://10.1.1.1"),
+		sm.ActivePathFromID("100"):            eu,
+	}
+	for path, content := range files {
+		if err := afero.WriteFile(sm.Fs, path, []byte(content), utils.KonfPerm); err != nil {
+			t.Fatalf("Could not create file, please check test code: %v", err)
+		}
+	}
+	// the current shell has inherited the kubeconfig of session 100
+	t.Setenv("KUBECONFIG", sm.ActivePathFromID("100"))
+	t.Setenv(sessionEnv, "shell_1")
+
+	sc := &syncCmd{sm: sm, isAlive: mockIsAlive}
+	if err := sc.sync(sc.cmd, []string{}); err != nil {
+		t.Fatalf("Exp no error, got %v", err)
+	}
+
+	b, err := afero.ReadFile(sm.Fs, sm.ActivePathFromID("100"))
+	if err != nil {
+		t.Fatalf("Could not read active konf: %v", err)
+	}
+	if diff := cmp.Diff(eu, string(b)); diff != "" {
+		t.Errorf("Exp inherited kubeconfig to be unchanged, but got diff (-want +got):\n%s", diff)
+	}
+
+	b, err = afero.ReadFile(sm.Fs, sm.ActivePathFromID("shell_1"))
+	if err != nil {
+		t.Fatalf("Exp active konf of the current shell to be created, got %v", err)
+	}
+	var conf k8s.Config
+	if err := yaml.Unmarshal(b, &conf); err != nil {
+		t.Fatalf("Could not parse active konf: %v", err)
+	}
+	if server, ns := conf.Clusters[0].Cluster.Server, conf.Contexts[0].Context.Namespace; server != "https://10.1.1.1" || ns != "team-a" {
+		t.Errorf("Exp refreshed konf with server %q and namespace %q, got %q and %q", "https://10.1.1.1", "team-a", server, ns)
+	}
+}
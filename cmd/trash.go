@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/simontheleg/konf-go/config"
+	"github.com/simontheleg/konf-go/log"
+	"github.com/simontheleg/konf-go/prompt"
+	"github.com/simontheleg/konf-go/store"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+type trashCmd struct {
+	cmd *cobra.Command
+}
+
+func newTrashCmd() *trashCmd {
+	tc := &trashCmd{}
+
+	tc.cmd = &cobra.Command{
+		Use:   "trash",
+		Short: "Manage deleted kubeconfigs",
+		Long: `Deleted konfs are kept in the trash, until it is emptied.
+
+Use 'konf restore' to move a konf from the trash back into the store`,
+		Args: cobra.NoArgs,
+	}
+
+	tc.cmd.AddCommand(newTrashListCmd().cmd)
+	tc.cmd.AddCommand(newTrashEmptyCmd().cmd)
+
+	return tc
+}
+
+type trashListCmd struct {
+	sm *store.Storemanager
+
+	cmd *cobra.Command
+}
+
+func newTrashListCmd() *trashListCmd {
+	fs := afero.NewOsFs()
+	sm := &store.Storemanager{Fs: fs, Trashdir: config.TrashDir()}
+	lc := &trashListCmd{
+		sm: sm,
+	}
+
+	lc.cmd = &cobra.Command{
+		Use:   "list",
+		Short: "List deleted kubeconfigs",
+		Long:  `List all konfs in the trash, the most recently deleted first`,
+		RunE:  lc.list,
+		Args:  cobra.NoArgs,
+	}
+
+	return lc
+}
+
+func (c *trashListCmd) list(cmd *cobra.Command, args []string) error {
+	trashed, err := c.sm.TrashedKonfs()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, t := range trashed {
+		fmt.Fprintf(w, "%s\tdeleted %s\n", t.ID, prompt.TimeAgo(t.DeletedAt, now))
+	}
+
+	return w.Flush()
+}
+
+type trashEmptyCmd struct {
+	sm         *store.Storemanager
+	confirm    prompt.ConfirmFunc
+	isTerminal func() bool
+
+	olderThan ageValue
+	dryRun    bool
+	yes       bool
+
+	cmd *cobra.Command
+}
+
+func newTrashEmptyCmd() *trashEmptyCmd {
+	fs := afero.NewOsFs()
+	sm := &store.Storemanager{Fs: fs, Trashdir: config.TrashDir()}
+	ec := &trashEmptyCmd{
+		sm:         sm,
+		confirm:    prompt.Confirm,
+		isTerminal: prompt.IsTerminal,
+	}
+
+	ec.cmd = &cobra.Command{
+		Use:   "empty",
+		Short: "Permanently remove deleted kubeconfigs",
+		Long: `Permanently remove konfs from the trash. They cannot be restored afterwards.
+
+Removing konfs from the trash has to be confirmed. When not run interactively,
+konfs are only removed if --yes is given
+
+Examples:
+-> 'trash empty' remove all konfs from the trash
+-> 'trash empty --older-than 30d' remove konfs that have been deleted more than 30 days ago
+-> 'trash empty --dry-run' only print the konfs that would be removed
+`,
+		RunE: ec.empty,
+		Args: cobra.NoArgs,
+	}
+
+	ec.cmd.Flags().Var(&ec.olderThan, "older-than", "only remove konfs that have been deleted longer ago than this, e.g. 30d or 12h")
+	ec.cmd.Flags().BoolVar(&ec.dryRun, "dry-run", false, "only print the konfs that would be removed (default is false)")
+	ec.cmd.Flags().BoolVarP(&ec.yes, "yes", "y", false, "remove without asking for confirmation (default is false)")
+
+	return ec
+}
+
+func (c *trashEmptyCmd) empty(cmd *cobra.Command, args []string) error {
+	now := time.Now()
+	trashed, err := trashedBefore(c.sm, time.Duration(c.olderThan), now)
+	if err != nil {
+		return err
+	}
+	if len(trashed) == 0 {
+		log.Info("Nothing to remove from the trash")
+		return nil
+	}
+
+	if c.dryRun {
+		for _, t := range trashed {
+			fmt.Println(t.ID)
+		}
+		return nil
+	}
+
+	if !c.yes {
+		if !c.isTerminal() {
+			return fmt.Errorf("refusing to permanently remove %d konf(s) without confirmation, use --yes to remove them anyway", len(trashed))
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "The following %d konf(s) will be removed permanently:", len(trashed))
+		for _, t := range trashed {
+			fmt.Fprintf(&b, "\n  %s (deleted %s)", t.ID, prompt.TimeAgo(t.DeletedAt, now))
+		}
+		log.Info("%s", b.String())
+
+		ok, err := c.confirm(fmt.Sprintf("Permanently remove %d konf(s)", len(trashed)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.Info("Emptying the trash aborted")
+			return nil
+		}
+	}
+
+	removed, err := emptyTrash(c.sm, trashed)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Removed %d konf(s) from the trash", len(removed))
+	return nil
+}
+
+// trashedBefore returns all konfs in the trash, that have been deleted at
+// least olderThan before now
+func trashedBefore(sm *store.Storemanager, olderThan time.Duration, now time.Time) ([]*store.TrashedKonf, error) {
+	trashed, err := sm.TrashedKonfs()
+	if err != nil {
+		return nil, err
+	}
+
+	old := []*store.TrashedKonf{}
+	for _, t := range trashed {
+		if now.Sub(t.DeletedAt) >= olderThan {
+			old = append(old, t)
+		}
+	}
+	return old, nil
+}
+
+// emptyTrash permanently removes the supplied konfs from the trash. It returns
+// the removed konfs
+func emptyTrash(sm *store.Storemanager, trashed []*store.TrashedKonf) ([]*store.TrashedKonf, error) {
+	removed := []*store.TrashedKonf{}
+	for _, t := range trashed {
+		if err := sm.RemoveTrashed(t); err != nil {
+			return removed, err
+		}
+		removed = append(removed, t)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/store"
+	"github.com/simontheleg/konf-go/testhelper"
+)
+
+func TestEmptyTrash(t *testing.T) {
+	storeDir := "./konf/store"
+	activeDir := "./konf/active"
+	stateDir := "./konf/state"
+	trashDir := "./konf/trash"
+	fm := testhelper.FilesystemManager{Storedir: storeDir, Activedir: activeDir}
+	now := time.Date(2022, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	tt := map[string]struct {
+		olderThan  time.Duration
+		expRemoved []konf.KonfID
+		expKept    []konf.KonfID
+	}{
+		"remove everything": {
+			0,
+			[]konf.KonfID{"dev-eu_dev-eu-1", "dev-asia_dev-asia-1"},
+			[]konf.KonfID{},
+		},
+		"remove old konfs only": {
+			30 * 24 * time.Hour,
+			[]konf.KonfID{"dev-asia_dev-asia-1"},
+			[]konf.KonfID{"dev-eu_dev-eu-1"},
+		},
+		"nothing is old enough": {
+			60 * 24 * time.Hour,
+			[]konf.KonfID{},
+			[]konf.KonfID{"dev-eu_dev-eu-1", "dev-asia_dev-asia-1"},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			f := testhelper.FSWithFiles(fm.StoreDir, fm.SingleClusterSingleContextEU, fm.SingleClusterSingleContextASIA)()
+			sm := &store.Storemanager{Fs: f, Activedir: activeDir, Storedir: storeDir, Statedir: stateDir, Trashdir: trashDir}
+			if _, err := sm.Trash("dev-asia_dev-asia-1", now.Add(-31*24*time.Hour)); err != nil {
+				t.Fatalf("Could not create trash, please check test code: %v", err)
+			}
+			if _, err := sm.Trash("dev-eu_dev-eu-1", now.Add(-time.Hour)); err != nil {
+				t.Fatalf("Could not create trash, please check test code: %v", err)
+			}
+
+			old, err := trashedBefore(sm, tc.olderThan, now)
+			if err != nil {
+				t.Fatalf("Exp no error, got %v", err)
+			}
+			removed, err := emptyTrash(sm, old)
+			if err != nil {
+				t.Fatalf("Exp no error, got %v", err)
+			}
+
+			trashed, err := sm.TrashedKonfs()
+			if err != nil {
+				t.Fatalf("Exp no error, got %v", err)
+			}
+
+			if ids := trashedIDs(removed); !cmp.Equal(ids, tc.expRemoved) {
+				t.Errorf("Exp removed konfs %v, got %v", tc.expRemoved, ids)
+			}
+			if ids := trashedIDs(trashed); !cmp.Equal(ids, tc.expKept) {
+				t.Errorf("Exp kept konfs %v, got %v", tc.expKept, ids)
+			}
+		})
+	}
+}
+
+func TestTrashEmpty(t *testing.T) {
+	tt := map[string]struct {
+		isTerminal bool
+		answer     bool
+		dryRun     bool
+		yes        bool
+		expConfirm bool
+		expKept    int
+		expErr     error
+	}{
+		"confirmed":           {isTerminal: true, answer: true, expConfirm: true, expKept: 0},
+		"aborted":             {isTerminal: true, answer: false, expConfirm: true, expKept: 2},
+		"yes":                 {isTerminal: true, yes: true, expKept: 0},
+		"dry run":             {isTerminal: true, dryRun: true, expKept: 2},
+		"no terminal":         {isTerminal: false, expKept: 2, expErr: fmt.Errorf("refusing to permanently remove 2 konf(s) without confirmation, use --yes to remove them anyway")},
+		"no terminal but yes": {isTerminal: false, yes: true, expKept: 0},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			fm := testhelper.FilesystemManager{Storedir: "./konf/store", Activedir: "./konf/active"}
+			f := testhelper.FSWithFiles(fm.StoreDir, fm.SingleClusterSingleContextEU, fm.SingleClusterSingleContextASIA)()
+			sm := &store.Storemanager{Fs: f, Storedir: "./konf/store", Statedir: "./konf/state", Trashdir: "./konf/trash"}
+			for _, id := range []konf.KonfID{"dev-eu_dev-eu-1", "dev-asia_dev-asia-1"} {
+				if _, err := sm.Trash(id, time.Now()); err != nil {
+					t.Fatalf("Could not create trash, please check test code: %v", err)
+				}
+			}
+
+			confirmed := false
+			ec := &trashEmptyCmd{
+				sm:         sm,
+				confirm:    func(string) (bool, error) { confirmed = true; return tc.answer, nil },
+				isTerminal: func() bool { return tc.isTerminal },
+				dryRun:     tc.dryRun,
+				yes:        tc.yes,
+			}
+
+			err := ec.empty(ec.cmd, []string{})
+			if !testhelper.EqualError(tc.expErr, err) {
+				t.Errorf("Exp error %q, got %q", tc.expErr, err)
+			}
+			if confirmed != tc.expConfirm {
+				t.Errorf("Exp confirmation to be asked: %t, got %t", tc.expConfirm, confirmed)
+			}
+
+			trashed, err := sm.TrashedKonfs()
+			if err != nil {
+				t.Fatalf("Exp no error, got %v", err)
+			}
+			if len(trashed) != tc.expKept {
+				t.Errorf("Exp %d konfs to be kept in the trash, got %d", tc.expKept, len(trashed))
+			}
+		})
+	}
+}
+
+func trashedIDs(trashed []*store.TrashedKonf) []konf.KonfID {
+	ids := []konf.KonfID{}
+	for _, t := range trashed {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
@@ -1,17 +1,55 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
+	"slices"
+	"time"
+
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
 )
 
 var curConf *Config = &Config{}
 
-// Config describes all values that can currently be configured for konf
+// Config describes all values that can currently be configured for konf.
+// Fields without a json tag can only be set using flags
 type Config struct {
-	KonfDir string
-	Silent  bool
+	KonfDir string `json:"-"`
+	Silent  bool   `json:"-"`
+	// Sort is the order in which konfs are listed in the selection prompt.
+	// Either SortFrecency or SortAlphabetical
+	Sort string `json:"sort,omitempty"`
+	// Columns are the columns shown in the selection prompt in the order they
+	// are shown. See ValidColumns for all possible columns
+	Columns []string `json:"columns,omitempty"`
+	// Picker configures an external fuzzy finder for all selection prompts
+	Picker PickerConfig `json:"picker,omitempty"`
+	// CleanupInterval is the minimum number of minutes between two automatic
+	// cleanups of leftover active konfs during 'konf set'. 0 disables them
+	CleanupInterval int `json:"cleanupInterval,omitempty"`
+}
+
+// PickerConfig describes an external fuzzy finder like fzf. If Command is
+// empty, konf's builtin prompt is used. If Args are empty, konf uses defaults
+// for well known fuzzy finders
+type PickerConfig struct {
+	Command string   `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
 }
 
+// ValidColumns are all columns that can be shown in the selection prompt
+var ValidColumns = []string{"context", "cluster", "file", "namespace", "server", "user", "tags", "description", "last-used"}
+
+const (
+	// SortFrecency lists the most frequently and recently used konfs first
+	SortFrecency = "frecency"
+	// SortAlphabetical lists konfs in alphabetical order
+	SortAlphabetical = "alphabetical"
+)
+
 // DefaultConfig returns an initialized config based on the users HomeDir
 func DefaultConfig() (*Config, error) {
 	c := &Config{}
@@ -23,10 +61,49 @@ func DefaultConfig() (*Config, error) {
 
 	c.KonfDir = home + "/.kube/konfs"
 	c.Silent = false
+	c.Sort = SortFrecency
+	c.Columns = []string{"context", "cluster", "file"}
+	c.CleanupInterval = 60
 
 	return c, nil
 }
 
+// LoadFile applies all values from the config file in the KonfDir of c on top
+// of c. A missing config file is not an error, as it is entirely optional
+func LoadFile(f afero.Fs, c *Config) error {
+	path := c.KonfDir + "/config.yaml"
+	b, err := afero.ReadFile(f, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := yaml.Unmarshal(b, c); err != nil {
+		return fmt.Errorf("could not parse config file %q: %v", path, err)
+	}
+
+	if c.Sort != SortFrecency && c.Sort != SortAlphabetical {
+		return fmt.Errorf("invalid value %q for sort in config file %q. Valid values are %q and %q", c.Sort, path, SortFrecency, SortAlphabetical)
+	}
+
+	if len(c.Columns) == 0 {
+		return fmt.Errorf("invalid value for columns in config file %q. At least one column is required", path)
+	}
+	for _, col := range c.Columns {
+		if !slices.Contains(ValidColumns, col) {
+			return fmt.Errorf("invalid column %q in config file %q. Valid columns are %v", col, path, ValidColumns)
+		}
+	}
+
+	if c.CleanupInterval < 0 {
+		return fmt.Errorf("invalid value %d for cleanupInterval in config file %q. It must not be negative", c.CleanupInterval, path)
+	}
+
+	return nil
+}
+
 // SetGlobalConfig sets the config to the config supplied as its argument
 func SetGlobalConfig(or *Config) {
 	curConf = or
@@ -35,6 +112,11 @@ func SetGlobalConfig(or *Config) {
 // Currently there is no need to customize store and active configs individually.
 // Setting the konfDir should be enough
 
+// KonfDir returns the currently configured konfs directory
+func KonfDir() string {
+	return curConf.KonfDir
+}
+
 // ActiveDir returns the currently configured active directory
 func ActiveDir() string {
 	return curConf.KonfDir + "/active"
@@ -45,7 +127,41 @@ func StoreDir() string {
 	return curConf.KonfDir + "/store"
 }
 
+// StateDir returns the currently configured state directory. It contains
+// everything konf needs to remember between invocations, that is not a konf
+// itself
+func StateDir() string {
+	return curConf.KonfDir + "/state"
+}
+
+// TrashDir returns the currently configured trash directory. It contains all
+// konfs that have been deleted, until the trash is emptied
+func TrashDir() string {
+	return curConf.KonfDir + "/trash"
+}
+
 // LatestKonfFilePath returns the currently configured latest konf file
 func LatestKonfFilePath() string {
 	return curConf.KonfDir + "/latestkonf"
 }
+
+// Sort returns the currently configured sort order of the selection prompt
+func Sort() string {
+	return curConf.Sort
+}
+
+// Columns returns the currently configured columns of the selection prompt
+func Columns() []string {
+	return curConf.Columns
+}
+
+// Picker returns the currently configured external fuzzy finder
+func Picker() PickerConfig {
+	return curConf.Picker
+}
+
+// CleanupInterval returns the currently configured minimum time between two
+// automatic cleanups. A zero duration means automatic cleanups are disabled
+func CleanupInterval() time.Duration {
+	return time.Duration(curConf.CleanupInterval) * time.Minute
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+)
+
+func TestLoadFile(t *testing.T) {
+	tt := map[string]struct {
+		content string
+		exp     *Config
+		expErr  error
+	}{
+		"no config file": {
+			"",
+			&Config{KonfDir: "./konf", Sort: SortFrecency, Columns: []string{"context"}},
+			nil,
+		},
+		"alphabetical sort": {
+			"sort: alphabetical\n",
+			&Config{KonfDir: "./konf", Sort: SortAlphabetical, Columns: []string{"context"}},
+			nil,
+		},
+		"konfDir cannot be overwritten": {
+			"konfDir: ./other\n",
+			&Config{KonfDir: "./konf", Sort: SortFrecency, Columns: []string{"context"}},
+			nil,
+		},
+		"custom columns": {
+			"columns: [context, namespace, last-used]\n",
+			&Config{KonfDir: "./konf", Sort: SortFrecency, Columns: []string{"context", "namespace", "last-used"}},
+			nil,
+		},
+		"external picker": {
+			"picker:\n  command: fzf\n  args: [--multi]\n",
+			&Config{KonfDir: "./konf", Sort: SortFrecency, Columns: []string{"context"}, Picker: PickerConfig{Command: "fzf", Args: []string{"--multi"}}},
+			nil,
+		},
+		"cleanup interval": {
+			"cleanupInterval: 15\n",
+			&Config{KonfDir: "./konf", Sort: SortFrecency, Columns: []string{"context"}, CleanupInterval: 15},
+			nil,
+		},
+		"negative cleanup interval": {
+			"cleanupInterval: -1\n",
+			nil,
+			fmt.Errorf("invalid value %d for cleanupInterval in config file %q. It must not be negative", -1, "./konf/config.yaml"),
+		},
+		"invalid column": {
+			"columns: [context, color]\n",
+			nil,
+			fmt.Errorf("invalid column %q in config file %q. Valid columns are %v", "color", "./konf/config.yaml", ValidColumns),
+		},
+		"invalid sort": {
+			"sort: random\n",
+			nil,
+			fmt.Errorf("invalid value %q for sort in config file %q. Valid values are %q and %q", "random", "./konf/config.yaml", SortFrecency, SortAlphabetical),
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			f := afero.NewMemMapFs()
+			if tc.content != "" {
+				afero.WriteFile(f, "./konf/config.yaml", []byte(tc.content), 0600)
+			}
+
+			c := &Config{KonfDir: "./konf", Sort: SortFrecency, Columns: []string{"context"}}
+			err := LoadFile(f, c)
+
+			if !(err == nil && tc.expErr == nil || err != nil && tc.expErr != nil && err.Error() == tc.expErr.Error()) {
+				t.Errorf("Exp err %q, got %q", tc.expErr, err)
+			}
+
+			if tc.exp != nil && !cmp.Equal(c, tc.exp) {
+				t.Errorf("Exp and given configs differ: \n '%s'", cmp.Diff(tc.exp, c))
+			}
+		})
+	}
+}
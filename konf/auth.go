@@ -0,0 +1,87 @@
+package konf
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	k8s "k8s.io/client-go/tools/clientcmd/api/v1"
+)
+
+// AuthMethod returns a short description of how a user authenticates against
+// a cluster. It never contains any credentials, so it is safe to be displayed
+func AuthMethod(a *k8s.AuthInfo) string {
+	switch {
+	case a == nil:
+		return "none"
+	case a.Exec != nil:
+		// only the name of the binary is used, as its args or env can contain secrets
+		return fmt.Sprintf("exec plugin (%s)", filepath.Base(a.Exec.Command))
+	case a.AuthProvider != nil:
+		return fmt.Sprintf("auth provider (%s)", a.AuthProvider.Name)
+	case len(a.ClientCertificateData) > 0 || a.ClientCertificate != "":
+		return "client certificate"
+	case a.Token != "" || a.TokenFile != "":
+		return "token"
+	case a.Username != "":
+		return "basic auth"
+	default:
+		return "none"
+	}
+}
+
+// CredentialExpiry returns when the credentials of a user expire. This only
+// works for credentials that are part of the kubeconfig itself, namely
+// embedded client certificates and JWT tokens. For all other credentials
+// found is false
+func CredentialExpiry(a *k8s.AuthInfo) (expiry time.Time, found bool) {
+	if a == nil {
+		return time.Time{}, false
+	}
+
+	if len(a.ClientCertificateData) > 0 {
+		block, _ := pem.Decode(a.ClientCertificateData)
+		if block == nil {
+			return time.Time{}, false
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return cert.NotAfter, true
+	}
+
+	if a.Token != "" {
+		return jwtExpiry(a.Token)
+	}
+
+	return time.Time{}, false
+}
+
+// jwtExpiry reads the exp claim of a JWT without verifying it. Verification
+// is not needed, as the expiry is only displayed to the user
+func jwtExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	claims := struct {
+		Exp int64 `json:"exp"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(claims.Exp, 0), true
+}
@@ -0,0 +1,77 @@
+package konf
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	k8s "k8s.io/client-go/tools/clientcmd/api/v1"
+)
+
+func TestAuthMethod(t *testing.T) {
+	tt := map[string]struct {
+		auth *k8s.AuthInfo
+		exp  string
+	}{
+		"no user":            {nil, "none"},
+		"empty user":         {&k8s.AuthInfo{}, "none"},
+		"token":              {&k8s.AuthInfo{Token: "secret"}, "token"},
+		"token file":         {&k8s.AuthInfo{TokenFile: "/var/token"}, "token"},
+		"client certificate": {&k8s.AuthInfo{ClientCertificateData: []byte("cert"), ClientKeyData: []byte("key")}, "client certificate"},
+		"basic auth":         {&k8s.AuthInfo{Username: "admin", Password: "secret"}, "basic auth"},
+		"auth provider":      {&k8s.AuthInfo{AuthProvider: &k8s.AuthProviderConfig{Name: "oidc"}}, "auth provider (oidc)"},
+		"exec plugin":        {&k8s.AuthInfo{Exec: &k8s.ExecConfig{Command: "/usr/local/bin/aws", Args: []string{"--secret"}}}, "exec plugin (aws)"},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if res := AuthMethod(tc.auth); res != tc.exp {
+				t.Errorf("Exp auth method %q, got %q", tc.exp, res)
+			}
+		})
+	}
+}
+
+func TestCredentialExpiry(t *testing.T) {
+	notAfter := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Could not generate key, please check test code: %v", err)
+	}
+	tmpl := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: notAfter.Add(-time.Hour), NotAfter: notAfter}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Could not create certificate, please check test code: %v", err)
+	}
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	jwt := "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString([]byte(`{"exp":1893456000}`)) + ".sig"
+
+	tt := map[string]struct {
+		auth     *k8s.AuthInfo
+		expTime  time.Time
+		expFound bool
+	}{
+		"client certificate":  {&k8s.AuthInfo{ClientCertificateData: cert}, notAfter, true},
+		"invalid certificate": {&k8s.AuthInfo{ClientCertificateData: []byte("cert")}, time.Time{}, false},
+		"jwt":                 {&k8s.AuthInfo{Token: jwt}, notAfter, true},
+		"opaque token":        {&k8s.AuthInfo{Token: "secret"}, time.Time{}, false},
+		"exec plugin":         {&k8s.AuthInfo{Exec: &k8s.ExecConfig{Command: "aws"}}, time.Time{}, false},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			res, found := CredentialExpiry(tc.auth)
+			if found != tc.expFound || !res.Equal(tc.expTime) {
+				t.Errorf("Exp expiry %s (found: %t), got %s (found: %t)", tc.expTime, tc.expFound, res, found)
+			}
+		})
+	}
+}
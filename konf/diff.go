@@ -0,0 +1,376 @@
+package konf
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	k8s "k8s.io/client-go/tools/clientcmd/api/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// ChangeType describes how a value differs between two kubeconfigs
+type ChangeType string
+
+const (
+	Added    ChangeType = "+"
+	Removed  ChangeType = "-"
+	Modified ChangeType = "~"
+)
+
+// Redacted is displayed instead of values that contain credentials
+const Redacted = "<redacted>"
+
+// Change is a single value that differs between two kubeconfigs
+type Change struct {
+	// Path identifies the value, e.g. "clusters[dev-eu].cluster.server".
+	// Elements of named lists like clusters, contexts and users are
+	// addressed by their name instead of their index
+	Path string
+	Type ChangeType
+	// Old and New are nil if the value has been added or removed respectively
+	Old, New interface{}
+	// Secret is true if the value contains credentials and must not be displayed
+	Secret bool
+
+	steps []step
+}
+
+// step is a single element of the path of a change
+type step struct {
+	key string
+	// name is set if key refers to a named list, in which case the step
+	// points to the element with this name
+	name string
+}
+
+// secretKeys are the keys in a kubeconfig whose values contain credentials
+var secretKeys = map[string]bool{
+	"token":                   true,
+	"password":                true,
+	"client-key-data":         true,
+	"client-certificate-data": true,
+	"id-token":                true,
+	"refresh-token":           true,
+	"access-token":            true,
+	"client-secret":           true,
+}
+
+// String returns a single line describing the change. Credentials are
+// redacted
+func (c Change) String() string {
+	switch c.Type {
+	case Added:
+		return fmt.Sprintf("%s %s: %s", c.Type, c.Path, c.display(c.New))
+	case Removed:
+		return fmt.Sprintf("%s %s: %s", c.Type, c.Path, c.display(c.Old))
+	default:
+		return fmt.Sprintf("%s %s: %s -> %s", c.Type, c.Path, c.display(c.Old), c.display(c.New))
+	}
+}
+
+func (c Change) display(v interface{}) string {
+	if c.Secret {
+		return Redacted
+	}
+	v = redact(v, c.steps)
+	if s, ok := v.(string); ok {
+		return s
+	}
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Matches returns true if the change is at or below one of the supplied
+// paths. Without any paths, every change matches
+func (c Change) Matches(paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	for _, p := range paths {
+		if c.Path == p || strings.HasPrefix(c.Path, p+".") || strings.HasPrefix(c.Path, p+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// Structural returns true if the change adds or removes a whole cluster,
+// context or user, or changes the current context. Renaming any of them
+// results in such changes as well, as elements are identified by their name
+func (c Change) Structural() bool {
+	if len(c.steps) != 1 {
+		return false
+	}
+	return c.steps[0].key == "current-context" || (c.steps[0].name != "" && c.Type != Modified)
+}
+
+// Diff returns all values that differ between the kubeconfigs from and to,
+// ordered by their path
+func Diff(from, to *k8s.Config) ([]Change, error) {
+	a, err := toTree(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toTree(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []Change{}
+	diffValues(a, b, nil, false, &changes)
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// ApplyChanges applies changes created by Diff to conf and returns the
+// result. conf itself is not modified
+func ApplyChanges(conf *k8s.Config, changes []Change) (*k8s.Config, error) {
+	tree, err := toTree(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range changes {
+		if err := applyChange(tree, c); err != nil {
+			return nil, fmt.Errorf("could not apply change of %q: %v", c.Path, err)
+		}
+	}
+
+	b, err := json.Marshal(tree)
+	if err != nil {
+		return nil, err
+	}
+	res := &k8s.Config{}
+	if err := yaml.Unmarshal(b, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// toTree converts a kubeconfig into its generic representation, which uses the
+// same keys as the kubeconfig file
+func toTree(conf *k8s.Config) (map[string]interface{}, error) {
+	b, err := json.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+	tree := map[string]interface{}{}
+	if err := json.Unmarshal(b, &tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+func diffValues(a, b interface{}, steps []step, secret bool, changes *[]Change) {
+	ma, aIsMap := a.(map[string]interface{})
+	mb, bIsMap := b.(map[string]interface{})
+	if (aIsMap || a == nil) && (bIsMap || b == nil) && (len(ma) > 0 || len(mb) > 0) {
+		keys := map[string]bool{}
+		for k := range ma {
+			keys[k] = true
+		}
+		for k := range mb {
+			keys[k] = true
+		}
+		for k := range keys {
+			s := append(append([]step{}, steps...), step{key: k})
+			diffValues(ma[k], mb[k], s, secret || isSecret(s), changes)
+		}
+		return
+	}
+
+	na, aIsNamed := namedList(a)
+	nb, bIsNamed := namedList(b)
+	if aIsNamed && bIsNamed && (len(na) > 0 || len(nb) > 0) {
+		names := map[string]bool{}
+		for n := range na {
+			names[n] = true
+		}
+		for n := range nb {
+			names[n] = true
+		}
+		last := steps[len(steps)-1]
+		for n := range names {
+			s := append(append([]step{}, steps[:len(steps)-1]...), step{key: last.key, name: n})
+			// elements that only exist on one side are a single change, as they cannot exist without their name
+			if na[n] == nil || nb[n] == nil {
+				addChange(na[n], nb[n], s, secret, changes)
+				continue
+			}
+			diffValues(na[n], nb[n], s, secret, changes)
+		}
+		return
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return
+	}
+	addChange(a, b, steps, secret, changes)
+}
+
+func addChange(a, b interface{}, steps []step, secret bool, changes *[]Change) {
+	c := Change{Path: pathOf(steps), Old: a, New: b, Secret: secret, steps: steps}
+	switch {
+	case a == nil:
+		c.Type = Added
+	case b == nil:
+		c.Type = Removed
+	default:
+		c.Type = Modified
+	}
+	*changes = append(*changes, c)
+}
+
+// isSecret returns true if the path points to a value that contains
+// credentials
+func isSecret(steps []step) bool {
+	return secretKeys[steps[len(steps)-1].key] || isExecSecret(steps)
+}
+
+// redact returns a copy of v, in which all values containing credentials have
+// been replaced. steps is the path of v
+func redact(v interface{}, steps []step) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		res := map[string]interface{}{}
+		for k, e := range t {
+			s := append(append([]step{}, steps...), step{key: k})
+			if isSecret(s) {
+				res[k] = Redacted
+				continue
+			}
+			res[k] = redact(e, s)
+		}
+		return res
+	case []interface{}:
+		res := []interface{}{}
+		for _, e := range t {
+			res = append(res, redact(e, steps))
+		}
+		return res
+	default:
+		return v
+	}
+}
+
+// isExecSecret returns true if the path points to the args or env of an exec
+// plugin, as they are commonly used to pass credentials
+func isExecSecret(steps []step) bool {
+	if len(steps) < 2 || steps[len(steps)-2].key != "exec" {
+		return false
+	}
+	k := steps[len(steps)-1].key
+	return k == "args" || k == "env"
+}
+
+// namedList returns the elements of a list by their name, if every element
+// has one. A missing list counts as an empty named list
+func namedList(v interface{}) (map[string]interface{}, bool) {
+	if v == nil {
+		return map[string]interface{}{}, true
+	}
+	l, ok := v.([]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	named := map[string]interface{}{}
+	for _, e := range l {
+		m, ok := e.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		name, ok := m["name"].(string)
+		if !ok {
+			return nil, false
+		}
+		if _, dup := named[name]; dup {
+			return nil, false
+		}
+		named[name] = e
+	}
+	return named, true
+}
+
+func pathOf(steps []step) string {
+	var b strings.Builder
+	for i, s := range steps {
+		if i > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(s.key)
+		if s.name != "" {
+			fmt.Fprintf(&b, "[%s]", s.name)
+		}
+	}
+	return b.String()
+}
+
+func applyChange(tree map[string]interface{}, c Change) error {
+	if len(c.steps) == 0 {
+		return fmt.Errorf("change has no path")
+	}
+
+	cur := tree
+	for i, s := range c.steps {
+		last := i == len(c.steps)-1
+		if s.name == "" {
+			if last {
+				if c.Type == Removed {
+					delete(cur, s.key)
+				} else {
+					cur[s.key] = c.New
+				}
+				return nil
+			}
+			next, ok := cur[s.key].(map[string]interface{})
+			if !ok {
+				next = map[string]interface{}{}
+				cur[s.key] = next
+			}
+			cur = next
+			continue
+		}
+
+		l, _ := cur[s.key].([]interface{})
+		idx := -1
+		for j, e := range l {
+			if m, ok := e.(map[string]interface{}); ok && m["name"] == s.name {
+				idx = j
+				break
+			}
+		}
+		if last {
+			switch {
+			case c.Type == Removed && idx >= 0:
+				l = append(l[:idx], l[idx+1:]...)
+			case c.Type == Removed:
+			case idx >= 0:
+				l[idx] = c.New
+			default:
+				l = append(l, c.New)
+			}
+			cur[s.key] = l
+			return nil
+		}
+		if idx < 0 {
+			l = append(l, map[string]interface{}{"name": s.name})
+			idx = len(l) - 1
+			cur[s.key] = l
+		}
+		next, ok := l[idx].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s[%s] is not an object", s.key, s.name)
+		}
+		cur = next
+	}
+	return nil
+}
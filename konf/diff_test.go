@@ -0,0 +1,133 @@
+package konf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	k8s "k8s.io/client-go/tools/clientcmd/api/v1"
+)
+
+func diffTestConfig() *k8s.Config {
+	return &k8s.Config{
+		Clusters: []k8s.NamedCluster{{Name: "dev-eu-1", Cluster: k8s.Cluster{Server: "https://10.1.1.0"}}},
+		Contexts: []k8s.NamedContext{{Name: "dev-eu", Context: k8s.Context{Cluster: "dev-eu-1", AuthInfo: "dev-eu", Namespace: "kube-public"}}},
+		AuthInfos: []k8s.NamedAuthInfo{{Name: "dev-eu", AuthInfo: k8s.AuthInfo{
+			Token: "old-token",
+			Exec:  &k8s.ExecConfig{Command: "aws", Args: []string{"--secret", "old"}},
+		}}},
+		CurrentContext: "dev-eu",
+	}
+}
+
+func TestDiff(t *testing.T) {
+	from := diffTestConfig()
+	to := diffTestConfig()
+	to.Contexts[0].Context.Namespace = "team-a"
+	to.Clusters[0].Cluster.InsecureSkipTLSVerify = true
+	to.AuthInfos[0].AuthInfo.Token = "new-token"
+	to.AuthInfos[0].AuthInfo.Exec.Args = []string{"--secret", "new"}
+	to.Contexts = append(to.Contexts, k8s.NamedContext{Name: "dev-eu-admin", Context: k8s.Context{Cluster: "dev-eu-1", AuthInfo: "dev-eu"}})
+	to.AuthInfos = append(to.AuthInfos, k8s.NamedAuthInfo{Name: "admin", AuthInfo: k8s.AuthInfo{Username: "admin", Password: "hunter2"}})
+
+	changes, err := Diff(from, to)
+	if err != nil {
+		t.Fatalf("Exp no error, got %v", err)
+	}
+
+	res := []string{}
+	for _, c := range changes {
+		res = append(res, c.String())
+	}
+	exp := []string{
+		`+ clusters[dev-eu-1].cluster.insecure-skip-tls-verify: true`,
+		`+ contexts[dev-eu-admin]: {"context":{"cluster":"dev-eu-1","user":"dev-eu"},"name":"dev-eu-admin"}`,
+		`~ contexts[dev-eu].context.namespace: kube-public -> team-a`,
+		`+ users[admin]: {"name":"admin","user":{"password":"<redacted>","username":"admin"}}`,
+		`~ users[dev-eu].user.exec.args: <redacted> -> <redacted>`,
+		`~ users[dev-eu].user.token: <redacted> -> <redacted>`,
+	}
+	if !cmp.Equal(exp, res) {
+		t.Errorf("Exp changes %v, got %v", exp, res)
+	}
+	for _, r := range res {
+		for _, secret := range []string{"old-token", "new-token", "hunter2", "--secret"} {
+			if strings.Contains(r, secret) {
+				t.Errorf("Exp change %q to not contain secret %q", r, secret)
+			}
+		}
+	}
+
+	// applying all changes must turn from into to
+	applied, err := ApplyChanges(from, changes)
+	if err != nil {
+		t.Fatalf("Exp no error when applying changes, got %v", err)
+	}
+	if changes, _ := Diff(applied, to); len(changes) != 0 {
+		t.Errorf("Exp no changes after applying them, got %v", changes)
+	}
+	if from.Contexts[0].Context.Namespace != "kube-public" {
+		t.Errorf("Exp ApplyChanges to not modify the supplied config")
+	}
+}
+
+func TestChangeMatches(t *testing.T) {
+	c := Change{Path: "contexts[dev-eu].context.namespace"}
+
+	tt := map[string]struct {
+		paths []string
+		exp   bool
+	}{
+		"no paths":      {nil, true},
+		"exact path":    {[]string{"contexts[dev-eu].context.namespace"}, true},
+		"parent":        {[]string{"contexts[dev-eu]"}, true},
+		"list":          {[]string{"contexts"}, true},
+		"other element": {[]string{"contexts[dev-asia]"}, false},
+		"prefix of key": {[]string{"contexts[dev-eu].context.name"}, false},
+		"multiple":      {[]string{"users", "contexts[dev-eu].context"}, true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if res := c.Matches(tc.paths); res != tc.exp {
+				t.Errorf("Exp match to be %t, got %t", tc.exp, res)
+			}
+		})
+	}
+}
+
+func TestChangeStructural(t *testing.T) {
+	renamed := diffTestConfig()
+	renamed.Contexts[0].Name = "prod-eu"
+	renamed.CurrentContext = "prod-eu"
+	namespaced := diffTestConfig()
+	namespaced.Contexts[0].Context.Namespace = "team-a"
+	added := diffTestConfig()
+	added.AuthInfos = append(added.AuthInfos, k8s.NamedAuthInfo{Name: "admin", AuthInfo: k8s.AuthInfo{Username: "admin"}})
+
+	tt := map[string]struct {
+		to  *k8s.Config
+		exp map[string]bool
+	}{
+		"renamed context": {renamed, map[string]bool{"contexts[dev-eu]": true, "contexts[prod-eu]": true, "current-context": true}},
+		"changed value":   {namespaced, map[string]bool{"contexts[dev-eu].context.namespace": false}},
+		"added user":      {added, map[string]bool{"users[admin]": true}},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			changes, err := Diff(diffTestConfig(), tc.to)
+			if err != nil {
+				t.Fatalf("Exp no error, got %v", err)
+			}
+
+			res := map[string]bool{}
+			for _, c := range changes {
+				res[c.Path] = c.Structural()
+			}
+			if !cmp.Equal(tc.exp, res) {
+				t.Errorf("Exp structural changes %v, got %v", tc.exp, res)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io/fs"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -32,6 +34,64 @@ func IDFromProcessID(pid int) KonfID {
 	return KonfID(fmt.Sprint(pid))
 }
 
+// IDFromProcess creates a KonfID based on the supplied processID and the start
+// time of the process. The start time keeps the ID unique, even if the
+// processID is reused by the operating system later on. If start is empty, the
+// ID is equal to the one of IDFromProcessID
+func IDFromProcess(pid int, start string) KonfID {
+	if start == "" {
+		return IDFromProcessID(pid)
+	}
+	return KonfID(fmt.Sprintf("%d%s%s", pid, processSeparator, start))
+}
+
+// processSeparator separates the processID from the start time of the process
+const processSeparator = "-"
+
+// ProcessFromID returns the processID and the start time of the process an ID
+// has been created for by IDFromProcess. For IDs created by IDFromProcessID,
+// start is empty. IDs of sessions named after a token (see IDFromSessionToken)
+// are supported as well, as long as the token starts with a processID
+func ProcessFromID(id KonfID) (pid int, start string, err error) {
+	p, start, _ := strings.Cut(string(id), processSeparator)
+	p, _, _ = strings.Cut(p, tokenSeparator)
+	pid, err = strconv.Atoi(p)
+	if err != nil {
+		return 0, "", fmt.Errorf("%q is not based on a process id", id)
+	}
+	return pid, start, nil
+}
+
+// tokenSeparator separates the processID of the shell from the rest of a
+// session token
+const tokenSeparator = "."
+
+// validToken matches all session tokens that can safely be used as a filename.
+// The processSeparator is not allowed, so the start time can be told apart
+var validToken = regexp.MustCompile(`^[A-Za-z0-9._]{1,64}$`)
+
+// IDFromSessionToken creates a KonfID based on a session token, which has been
+// handed to konf by the shell. Tokens in the form of '<pid>.<anything>' refer
+// to the process of the shell, so startOf is used to append its start time,
+// just like IDFromProcess does. All other tokens are used as they are
+func IDFromSessionToken(token string, startOf func(pid int) (string, error)) (KonfID, error) {
+	if !validToken.MatchString(token) {
+		return "", fmt.Errorf("session token %q may only consist of up to 64 letters, digits, dots and underscores", token)
+	}
+
+	p, _, found := strings.Cut(token, tokenSeparator)
+	pid, err := strconv.Atoi(p)
+	if !found || err != nil {
+		return KonfID(token), nil
+	}
+
+	start, err := startOf(pid)
+	if err != nil || start == "" {
+		return KonfID(token), nil
+	}
+	return KonfID(token + processSeparator + start), nil
+}
+
 // IDFromFileInfo creates an ID from the name of a file
 func IDFromFileInfo(fi fs.FileInfo) KonfID {
 	return KonfID(strings.TrimSuffix(fi.Name(), filepath.Ext(fi.Name())))
@@ -3,6 +3,7 @@ package konf
 import (
 	"fmt"
 	"io/fs"
+	"strings"
 	"testing"
 	"time"
 
@@ -118,3 +119,82 @@ func TestIDFileValidityIntegration(t *testing.T) {
 		}
 	}
 }
+
+func TestIDFromProcess(t *testing.T) {
+	tt := map[string]struct {
+		pid   int
+		start string
+		exp   KonfID
+	}{
+		"with start time":    {1234, "5678", "1234-5678"},
+		"without start time": {1234, "", "1234"},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			id := IDFromProcess(tc.pid, tc.start)
+			if id != tc.exp {
+				t.Errorf("Exp id to be %s, got %s", tc.exp, id)
+			}
+
+			pid, start, err := ProcessFromID(id)
+			if err != nil || pid != tc.pid || start != tc.start {
+				t.Errorf("Exp id %s to be based on process %d started at %q, got %d started at %q (err: %v)", id, tc.pid, tc.start, pid, start, err)
+			}
+		})
+	}
+}
+
+func TestProcessFromIDInvalid(t *testing.T) {
+	for _, id := range []KonfID{"abc", "-1234", "dev-eu_dev-eu-1", ""} {
+		if _, _, err := ProcessFromID(id); err == nil {
+			t.Errorf("Exp id %q to not be based on a process id", id)
+		}
+	}
+}
+
+func TestIDFromSessionToken(t *testing.T) {
+	startOf := func(pid int) (string, error) {
+		switch pid {
+		case 1234:
+			return "5678", nil
+		case 42:
+			return "", fmt.Errorf("process does not exist")
+		default:
+			return "", nil
+		}
+	}
+
+	tt := map[string]struct {
+		token  string
+		expID  KonfID
+		expErr bool
+	}{
+		"token with pid":                    {"1234.987", "1234.987-5678", false},
+		"token with pid without start time": {"99.987", "99.987", false},
+		"token with pid of exited process":  {"42.987", "42.987", false},
+		"token without pid":                 {"vscode_1", "vscode_1", false},
+		"token with process separator":      {"my-session", "", true},
+		"token with slash":                  {"../session", "", true},
+		"token that is too long":            {strings.Repeat("a", 65), "", true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			id, err := IDFromSessionToken(tc.token, startOf)
+			if (err != nil) != tc.expErr {
+				t.Errorf("Exp error to be %t, got %v", tc.expErr, err)
+			}
+			if id != tc.expID {
+				t.Errorf("Exp id %q, got %q", tc.expID, id)
+			}
+		})
+	}
+}
+
+func TestProcessFromTokenID(t *testing.T) {
+	pid, start, err := ProcessFromID("1234.987-5678")
+	if err != nil || pid != 1234 || start != "5678" {
+		t.Errorf("Exp process 1234 started at %q, got %d started at %q (err: %v)", "5678", pid, start, err)
+	}
+}
@@ -0,0 +1,133 @@
+package konf
+
+import (
+	"fmt"
+
+	k8s "k8s.io/client-go/tools/clientcmd/api/v1"
+)
+
+// MergeKonfigs combines multiple konfs into a single kubeconfig, which can be
+// used to work with several contexts in one shell.
+//
+// Because konfs are imported from different kubeconfigs, their context,
+// cluster and user names can easily collide (e.g. lots of contexts are just
+// called "default"). To avoid this, all of them are renamed to the ID of the
+// konf they originate from. current-context is set to the primary konf
+func MergeKonfigs(konfs []*Konfig, primary KonfID) (*k8s.Config, error) {
+	if len(konfs) == 0 {
+		return nil, fmt.Errorf("cannot merge an empty list of konfs")
+	}
+
+	merged := &k8s.Config{
+		APIVersion: konfs[0].Kubeconfig.APIVersion,
+		Kind:       konfs[0].Kubeconfig.Kind,
+	}
+
+	foundPrimary := false
+	seen := map[KonfID]bool{}
+	for _, k := range konfs {
+		if len(k.Kubeconfig.Contexts) != 1 {
+			return nil, fmt.Errorf("konf %q must contain exactly one context to be merged, but has %d", k.Id, len(k.Kubeconfig.Contexts))
+		}
+		if seen[k.Id] {
+			return nil, fmt.Errorf("konf %q can only be merged once", k.Id)
+		}
+		seen[k.Id] = true
+		name := string(k.Id)
+
+		con := k.Kubeconfig.Contexts[0]
+		foundCluster := false
+		for _, cl := range k.Kubeconfig.Clusters {
+			if cl.Name == con.Context.Cluster {
+				cl.Name = name
+				merged.Clusters = append(merged.Clusters, cl)
+				foundCluster = true
+				break
+			}
+		}
+		if !foundCluster {
+			return nil, fmt.Errorf("cluster %q of konf %q does not exist", con.Context.Cluster, k.Id)
+		}
+
+		// contexts without a user are valid, e.g. for clusters that do not require authentication
+		if con.Context.AuthInfo != "" {
+			foundUser := false
+			for _, u := range k.Kubeconfig.AuthInfos {
+				if u.Name == con.Context.AuthInfo {
+					u.Name = name
+					merged.AuthInfos = append(merged.AuthInfos, u)
+					foundUser = true
+					break
+				}
+			}
+			if !foundUser {
+				return nil, fmt.Errorf("user %q of konf %q does not exist", con.Context.AuthInfo, k.Id)
+			}
+			con.Context.AuthInfo = name
+		}
+
+		con.Name = name
+		con.Context.Cluster = name
+		merged.Contexts = append(merged.Contexts, con)
+
+		if k.Id == primary {
+			merged.CurrentContext = name
+			foundPrimary = true
+		}
+	}
+
+	if !foundPrimary {
+		return nil, fmt.Errorf("primary konf %q is not part of the konfs to merge", primary)
+	}
+
+	return merged, nil
+}
+
+// IDsFromKubeconfig returns the IDs of all konfs that are part of a
+// kubeconfig. The ID of the current-context always comes first.
+//
+// A kubeconfig with a single context is treated like a regular konf, meaning
+// its ID is derived from its cluster and context. A kubeconfig with multiple
+// contexts is expected to be created by MergeKonfigs, so its context names are
+// the IDs themselves
+func IDsFromKubeconfig(conf *k8s.Config) []KonfID {
+	if len(conf.Contexts) == 0 {
+		return []KonfID{}
+	}
+
+	if len(conf.Contexts) == 1 {
+		con := conf.Contexts[0]
+		cluster := con.Context.Cluster
+		if len(conf.Clusters) > 0 {
+			cluster = conf.Clusters[0].Name
+		}
+		return []KonfID{IDFromClusterAndContext(cluster, con.Name)}
+	}
+
+	ids := []KonfID{}
+	for _, con := range conf.Contexts {
+		if con.Name == conf.CurrentContext {
+			ids = append([]KonfID{KonfID(con.Name)}, ids...)
+			continue
+		}
+		ids = append(ids, KonfID(con.Name))
+	}
+	return ids
+}
+
+// CurrentContext returns the context that current-context points to. If
+// current-context is not set or cannot be found, the first context is returned
+// instead. An error is only returned if the kubeconfig has no contexts at all
+func CurrentContext(conf *k8s.Config) (*k8s.NamedContext, error) {
+	if len(conf.Contexts) == 0 {
+		return nil, fmt.Errorf("contexts[] is empty in kubeconfig")
+	}
+
+	for i := range conf.Contexts {
+		if conf.Contexts[i].Name == conf.CurrentContext {
+			return &conf.Contexts[i], nil
+		}
+	}
+
+	return &conf.Contexts[0], nil
+}
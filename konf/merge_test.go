@@ -0,0 +1,145 @@
+package konf
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	k8s "k8s.io/client-go/tools/clientcmd/api/v1"
+)
+
+func konfForMerge(context, cluster, user string) *Konfig {
+	return &Konfig{
+		Id: IDFromClusterAndContext(cluster, context),
+		Kubeconfig: k8s.Config{
+			APIVersion:     "v1",
+			Kind:           "Config",
+			CurrentContext: context,
+			Clusters:       []k8s.NamedCluster{{Name: cluster, Cluster: k8s.Cluster{Server: "https://" + cluster}}},
+			Contexts:       []k8s.NamedContext{{Name: context, Context: k8s.Context{Cluster: cluster, AuthInfo: user, Namespace: "kube-public"}}},
+			AuthInfos:      []k8s.NamedAuthInfo{{Name: user}},
+		},
+	}
+}
+
+func TestMergeKonfigs(t *testing.T) {
+	eu := konfForMerge("default", "dev-eu-1", "admin")
+	asia := konfForMerge("default", "dev-asia-1", "admin")
+	missingUser := konfForMerge("default", "dev-asia-1", "admin")
+	missingUser.Kubeconfig.Contexts[0].Context.AuthInfo = "i-do-not-exist"
+	missingCluster := konfForMerge("default", "dev-asia-1", "admin")
+	missingCluster.Kubeconfig.Contexts[0].Context.Cluster = "i-do-not-exist"
+
+	tt := map[string]struct {
+		konfs   []*Konfig
+		primary KonfID
+		exp     *k8s.Config
+		expErr  error
+	}{
+		"colliding names": {
+			konfs:   []*Konfig{eu, asia},
+			primary: "default_dev-asia-1",
+			exp: &k8s.Config{
+				APIVersion:     "v1",
+				Kind:           "Config",
+				CurrentContext: "default_dev-asia-1",
+				Clusters: []k8s.NamedCluster{
+					{Name: "default_dev-eu-1", Cluster: k8s.Cluster{Server: "https://dev-eu-1"}},
+					{Name: "default_dev-asia-1", Cluster: k8s.Cluster{Server: "https://dev-asia-1"}},
+				},
+				Contexts: []k8s.NamedContext{
+					{Name: "default_dev-eu-1", Context: k8s.Context{Cluster: "default_dev-eu-1", AuthInfo: "default_dev-eu-1", Namespace: "kube-public"}},
+					{Name: "default_dev-asia-1", Context: k8s.Context{Cluster: "default_dev-asia-1", AuthInfo: "default_dev-asia-1", Namespace: "kube-public"}},
+				},
+				AuthInfos: []k8s.NamedAuthInfo{
+					{Name: "default_dev-eu-1"},
+					{Name: "default_dev-asia-1"},
+				},
+			},
+			expErr: nil,
+		},
+		"primary not part of konfs": {
+			konfs:   []*Konfig{eu, asia},
+			primary: "i-do-not-exist",
+			exp:     nil,
+			expErr:  fmt.Errorf("primary konf %q is not part of the konfs to merge", "i-do-not-exist"),
+		},
+		"konf supplied twice": {
+			konfs:   []*Konfig{eu, eu},
+			primary: "default_dev-eu-1",
+			exp:     nil,
+			expErr:  fmt.Errorf("konf %q can only be merged once", "default_dev-eu-1"),
+		},
+		"missing user": {
+			konfs:   []*Konfig{eu, missingUser},
+			primary: "default_dev-eu-1",
+			exp:     nil,
+			expErr:  fmt.Errorf("user %q of konf %q does not exist", "i-do-not-exist", "default_dev-asia-1"),
+		},
+		"missing cluster": {
+			konfs:   []*Konfig{eu, missingCluster},
+			primary: "default_dev-eu-1",
+			exp:     nil,
+			expErr:  fmt.Errorf("cluster %q of konf %q does not exist", "i-do-not-exist", "default_dev-asia-1"),
+		},
+		"no konfs": {
+			konfs:   []*Konfig{},
+			primary: "default_dev-eu-1",
+			exp:     nil,
+			expErr:  fmt.Errorf("cannot merge an empty list of konfs"),
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			res, err := MergeKonfigs(tc.konfs, tc.primary)
+
+			if !(err == nil && tc.expErr == nil || err != nil && tc.expErr != nil && err.Error() == tc.expErr.Error()) {
+				t.Errorf("Exp err %q, got %q", tc.expErr, err)
+			}
+
+			if !cmp.Equal(res, tc.exp) {
+				t.Errorf("Exp and given kubeconfigs differ: \n '%s'", cmp.Diff(tc.exp, res))
+			}
+		})
+	}
+
+	// merging must not modify the original konfs
+	if eu.Kubeconfig.Contexts[0].Name != "default" {
+		t.Errorf("Exp original konf to remain untouched, but context was renamed to %q", eu.Kubeconfig.Contexts[0].Name)
+	}
+}
+
+func TestIDsFromKubeconfig(t *testing.T) {
+	merged, err := MergeKonfigs([]*Konfig{konfForMerge("dev-eu", "dev-eu-1", "eu"), konfForMerge("dev-asia", "dev-asia-1", "asia")}, "dev-asia_dev-asia-1")
+	if err != nil {
+		t.Fatalf("Could not create merged konf, please check test code: %v", err)
+	}
+
+	tt := map[string]struct {
+		conf   *k8s.Config
+		expIDs []KonfID
+	}{
+		"single konf": {
+			&konfForMerge("dev-eu", "dev-eu-1", "eu").Kubeconfig,
+			[]KonfID{"dev-eu_dev-eu-1"},
+		},
+		"merged konf": {
+			merged,
+			[]KonfID{"dev-asia_dev-asia-1", "dev-eu_dev-eu-1"},
+		},
+		"no context": {
+			&k8s.Config{},
+			[]KonfID{},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			res := IDsFromKubeconfig(tc.conf)
+			if !cmp.Equal(res, tc.expIDs) {
+				t.Errorf("Exp ids %v, got %v", tc.expIDs, res)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package prompt
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/manifoldco/promptui"
+	"github.com/simontheleg/konf-go/config"
+	"github.com/simontheleg/konf-go/log"
+)
+
+// Configured returns the RunFunc of the picker configured by the user. If no
+// external picker is configured, Terminal is used
+func Configured() RunFunc {
+	p := config.Picker()
+	if p.Command == "" {
+		return Terminal
+	}
+	return External(p.Command, p.Args, Terminal)
+}
+
+// External returns a RunFunc, which delegates the selection to an external
+// fuzzy finder like fzf, sk or gum. If command cannot be found, the selection
+// is run using fallback instead.
+//
+// Every item is passed to the fuzzy finder as a line on stdin, which starts
+// with the index of the item followed by a tab and the item rendered by the
+// Inactive template of the prompt. The fuzzy finder is expected to print the
+// selected line on stdout. The details of each item are rendered into a file
+// named after its index in the directory $KONF_PREVIEW_DIR, so they can be
+// used in preview commands (e.g. 'cat $KONF_PREVIEW_DIR/{1}' for fzf)
+func External(command string, args []string, fallback RunFunc) RunFunc {
+	return func(s *promptui.Select) (int, error) {
+		path, err := exec.LookPath(command)
+		if err != nil {
+			log.Warn("Could not find picker %q, falling back to the builtin prompt: %v", command, err)
+			return fallback(s)
+		}
+
+		// args must not be overwritten, as the label differs between prompts
+		pargs := args
+		if len(pargs) == 0 {
+			pargs = defaultPickerArgs(command, fmt.Sprint(s.Label))
+		}
+
+		out, n, err := runPicker(path, pargs, s)
+		if err != nil {
+			return -1, err
+		}
+		return parseSelection(out, n)
+	}
+}
+
+// ExternalMulti works like External, but allows to select multiple items. The
+// fuzzy finder is expected to print every selected line on stdout. Custom
+// args need to enable multi-selection themselves (e.g. '--multi' for fzf)
+func ExternalMulti(command string, args []string, fallback MultiRunFunc) MultiRunFunc {
+	return func(s *promptui.Select) ([]int, error) {
+		path, err := exec.LookPath(command)
+		if err != nil {
+			log.Warn("Could not find picker %q, falling back to the builtin prompt: %v", command, err)
+			return fallback(s)
+		}
+
+		pargs := args
+		if len(pargs) == 0 {
+			pargs = defaultMultiPickerArgs(command, fmt.Sprint(s.Label))
+		}
+
+		out, n, err := runPicker(path, pargs, s)
+		if err != nil {
+			return nil, err
+		}
+		return parseSelections(out, n)
+	}
+}
+
+// runPicker passes the items of s to the fuzzy finder at path and returns its
+// output together with the number of items
+func runPicker(path string, args []string, s *promptui.Select) ([]byte, int, error) {
+	rows, details, err := renderItems(s)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	previewDir, err := os.MkdirTemp("", "konf-preview-")
+	if err != nil {
+		return nil, 0, err
+	}
+	defer os.RemoveAll(previewDir)
+	for i, d := range details {
+		if err := os.WriteFile(filepath.Join(previewDir, strconv.Itoa(i)), []byte(d), 0600); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	cmd := exec.Command(path, args...)
+	cmd.Env = append(os.Environ(), "KONF_PREVIEW_DIR="+previewDir)
+	cmd.Stdin = strings.NewReader(strings.Join(rows, "\n") + "\n")
+	// the fuzzy finder draws its ui on stderr or the tty directly, as stdout is reserved for the selection
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, 0, fmt.Errorf("prompt failed %v", err)
+	}
+
+	return out, len(rows), nil
+}
+
+// defaultPickerArgs returns sensible arguments for well known fuzzy finders.
+// The index column is hidden wherever possible
+func defaultPickerArgs(command, label string) []string {
+	switch filepath.Base(command) {
+	case "fzf", "sk":
+		return []string{
+			"--ansi",
+			"--delimiter=\t",
+			"--with-nth=2..",
+			"--header=" + label,
+			"--preview=cat \"$KONF_PREVIEW_DIR\"/{1}",
+			"--height=40%",
+			"--reverse",
+		}
+	case "gum":
+		return []string{"filter", "--header=" + label}
+	default:
+		return nil
+	}
+}
+
+// defaultMultiPickerArgs returns the arguments of defaultPickerArgs extended
+// by what is needed to select multiple items. For fzf and sk, ctrl-a toggles
+// all items that match the current query
+func defaultMultiPickerArgs(command, label string) []string {
+	args := defaultPickerArgs(command, label)
+	switch filepath.Base(command) {
+	case "fzf", "sk":
+		return append(args, "--multi", "--bind=ctrl-a:toggle-all")
+	case "gum":
+		return append(args, "--no-limit")
+	default:
+		return args
+	}
+}
+
+// renderItems renders every item of s into a single line and its details
+// using the templates of s
+func renderItems(s *promptui.Select) (rows, details []string, err error) {
+	funcs := promptui.FuncMap
+	inactive := "{{ . }}"
+	var detailsTpl string
+	if s.Templates != nil {
+		if s.Templates.FuncMap != nil {
+			funcs = s.Templates.FuncMap
+		}
+		if s.Templates.Inactive != "" {
+			inactive = s.Templates.Inactive
+		}
+		detailsTpl = s.Templates.Details
+	}
+
+	rowTmpl, err := template.New("row").Funcs(funcs).Parse(inactive)
+	if err != nil {
+		return nil, nil, err
+	}
+	detailsTmpl, err := template.New("details").Funcs(funcs).Parse(detailsTpl)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items := reflect.ValueOf(s.Items)
+	if items.Kind() != reflect.Slice {
+		return nil, nil, fmt.Errorf("items %v are not a slice", s.Items)
+	}
+
+	for i := 0; i < items.Len(); i++ {
+		item := items.Index(i).Interface()
+
+		buf := new(bytes.Buffer)
+		if err := rowTmpl.Execute(buf, item); err != nil {
+			return nil, nil, err
+		}
+		// every item must be exactly one line, as that is how fuzzy finders work
+		row := strings.ReplaceAll(buf.String(), "\n", " ")
+		rows = append(rows, strconv.Itoa(i)+"\t"+row)
+
+		buf.Reset()
+		if err := detailsTmpl.Execute(buf, item); err != nil {
+			return nil, nil, err
+		}
+		details = append(details, strings.TrimPrefix(buf.String(), "\n"))
+	}
+
+	return rows, details, nil
+}
+
+// parseSelection returns the index of the line selected by a fuzzy finder
+func parseSelection(out []byte, n int) (int, error) {
+	line, _, _ := strings.Cut(string(out), "\n")
+	if strings.TrimSpace(line) == "" {
+		return -1, fmt.Errorf("prompt failed nothing was selected")
+	}
+
+	idx, _, _ := strings.Cut(line, "\t")
+	sel, err := strconv.Atoi(strings.TrimSpace(idx))
+	if err != nil || sel < 0 || sel >= n {
+		return -1, fmt.Errorf("prompt failed could not parse selection %q", line)
+	}
+
+	return sel, nil
+}
+
+// parseSelections returns the indices of all lines selected by a fuzzy finder
+func parseSelections(out []byte, n int) ([]int, error) {
+	var sels []int
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		sel, err := parseSelection([]byte(line), n)
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+	}
+
+	if len(sels) == 0 {
+		return nil, fmt.Errorf("prompt failed nothing was selected")
+	}
+	return sels, nil
+}
@@ -0,0 +1,268 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/manifoldco/promptui"
+	"github.com/simontheleg/konf-go/store"
+)
+
+func TestExternal(t *testing.T) {
+	fallback := func(s *promptui.Select) (int, error) { return 2, nil }
+
+	tt := map[string]struct {
+		command string
+		args    []string
+		expSel  int
+		expErr  bool
+	}{
+		"select second line": {
+			"sh",
+			[]string{"-c", "sed -n 2p"},
+			1,
+			false,
+		},
+		"details are available as preview": {
+			"sh",
+			[]string{"-c", `cat > /dev/null; grep -q "details of c" "$KONF_PREVIEW_DIR/2" && printf '2\tc\n'`},
+			2,
+			false,
+		},
+		"picker was cancelled": {
+			"sh",
+			[]string{"-c", "exit 130"},
+			-1,
+			true,
+		},
+		"picker is not installed": {
+			"konf-picker-that-does-not-exist",
+			nil,
+			2,
+			false,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			s := &promptui.Select{
+				Items:     []string{"a", "b", "c"},
+				Templates: &promptui.SelectTemplates{Details: "details of {{ . }}"},
+			}
+
+			sel, err := External(tc.command, tc.args, fallback)(s)
+			if (err != nil) != tc.expErr {
+				t.Errorf("Exp error to be %t, got %v", tc.expErr, err)
+			}
+			if sel != tc.expSel {
+				t.Errorf("Exp selection %d, got %d", tc.expSel, sel)
+			}
+		})
+	}
+}
+
+func TestExternalDefaultArgsPerPrompt(t *testing.T) {
+	// a fake gum, which records its args and selects the first line
+	dir := t.TempDir()
+	argsLog := filepath.Join(dir, "args")
+	script := "#!/bin/sh\necho \"$*\" >> " + argsLog + "\nsed -n 1p\n"
+	if err := os.WriteFile(filepath.Join(dir, "gum"), []byte(script), 0700); err != nil {
+		t.Fatalf("Could not create fake picker, please check test code: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	fallback := func(s *promptui.Select) (int, error) { return -1, fmt.Errorf("fallback must not be used") }
+	multiFallback := func(s *promptui.Select) ([]int, error) { return nil, fmt.Errorf("fallback must not be used") }
+	single := External("gum", nil, fallback)
+	multi := ExternalMulti("gum", nil, multiFallback)
+
+	for _, label := range []string{"Select konf", "Select konf to restore"} {
+		if _, err := single(&promptui.Select{Label: label, Items: []string{"a"}}); err != nil {
+			t.Fatalf("Exp no error, got %v", err)
+		}
+	}
+	for _, label := range []string{"Select konfs to delete", "Select konfs to trash"} {
+		if _, err := multi(&promptui.Select{Label: label, Items: []string{"a"}}); err != nil {
+			t.Fatalf("Exp no error, got %v", err)
+		}
+	}
+
+	b, err := os.ReadFile(argsLog)
+	if err != nil {
+		t.Fatalf("Could not read args of fake picker: %v", err)
+	}
+	exp := []string{
+		"filter --header=Select konf",
+		"filter --header=Select konf to restore",
+		"filter --header=Select konfs to delete --no-limit",
+		"filter --header=Select konfs to trash --no-limit",
+	}
+	if res := strings.Split(strings.TrimSpace(string(b)), "\n"); !cmp.Equal(exp, res) {
+		t.Errorf("Exp picker args %q, got %q", exp, res)
+	}
+}
+
+func TestRenderItems(t *testing.T) {
+	options := []*store.Metadata{
+		{Context: "dev-eu", Cluster: "dev-eu-1", Server: "https://10.1.1.0", AuthMethod: "token", Pinned: true},
+		{Context: "dev-asia", Cluster: "dev-asia-1", Server: "https://10.1.1.1", AuthMethod: "token"},
+	}
+	inactive, active, _, fmap := NewTableOutputTemplates([]string{"context", "cluster"}, []int{8, 10})
+	s := &promptui.Select{
+		Items: options,
+		Templates: &promptui.SelectTemplates{
+			Active:   active,
+			Inactive: inactive,
+			Details:  "{{ .Server }}\n{{ .AuthMethod }}",
+			FuncMap:  fmap,
+		},
+	}
+
+	rows, details, err := renderItems(s)
+	if err != nil {
+		t.Fatalf("Exp no error, got %v", err)
+	}
+
+	expRows := []string{
+		"0\t  ★  dev-eu   | dev-eu-1   |",
+		"1\t     dev-asia | dev-asia-1 |",
+	}
+	if !cmp.Equal(rows, expRows) {
+		t.Errorf("Exp rows %q, got %q", expRows, rows)
+	}
+
+	expDetails := []string{"https://10.1.1.0\ntoken", "https://10.1.1.1\ntoken"}
+	if !cmp.Equal(details, expDetails) {
+		t.Errorf("Exp details %q, got %q", expDetails, details)
+	}
+}
+
+func TestParseSelection(t *testing.T) {
+	tt := map[string]struct {
+		out    string
+		expSel int
+		expErr error
+	}{
+		"line with index": {
+			"1\tdev-eu | dev-eu-1\n",
+			1,
+			nil,
+		},
+		"only the first line counts": {
+			"0\ta\n2\tc\n",
+			0,
+			nil,
+		},
+		"nothing selected": {
+			"",
+			-1,
+			fmt.Errorf("prompt failed nothing was selected"),
+		},
+		"index out of range": {
+			"3\td\n",
+			-1,
+			fmt.Errorf("prompt failed could not parse selection %q", "3\td"),
+		},
+		"no index": {
+			"dev-eu\n",
+			-1,
+			fmt.Errorf("prompt failed could not parse selection %q", "dev-eu"),
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			sel, err := parseSelection([]byte(tc.out), 3)
+			if !(err == nil && tc.expErr == nil || err != nil && tc.expErr != nil && err.Error() == tc.expErr.Error()) {
+				t.Errorf("Exp err %q, got %q", tc.expErr, err)
+			}
+			if sel != tc.expSel {
+				t.Errorf("Exp selection %d, got %d", tc.expSel, sel)
+			}
+		})
+	}
+}
+
+func TestExternalMulti(t *testing.T) {
+	fallback := func(s *promptui.Select) ([]int, error) { return []int{2}, nil }
+
+	tt := map[string]struct {
+		command string
+		args    []string
+		expSel  []int
+		expErr  bool
+	}{
+		"select two lines": {
+			"sh",
+			[]string{"-c", "sed -n '1p;3p'"},
+			[]int{0, 2},
+			false,
+		},
+		"picker was cancelled": {
+			"sh",
+			[]string{"-c", "exit 130"},
+			nil,
+			true,
+		},
+		"picker is not installed": {
+			"konf-picker-that-does-not-exist",
+			nil,
+			[]int{2},
+			false,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			s := &promptui.Select{Items: []string{"a", "b", "c"}}
+
+			sel, err := ExternalMulti(tc.command, tc.args, fallback)(s)
+			if (err != nil) != tc.expErr {
+				t.Errorf("Exp error to be %t, got %v", tc.expErr, err)
+			}
+			if !cmp.Equal(sel, tc.expSel) {
+				t.Errorf("Exp selection %v, got %v", tc.expSel, sel)
+			}
+		})
+	}
+}
+
+func TestParseSelections(t *testing.T) {
+	tt := map[string]struct {
+		out    string
+		expSel []int
+		expErr error
+	}{
+		"multiple lines": {
+			"0\ta\n2\tc\n",
+			[]int{0, 2},
+			nil,
+		},
+		"nothing selected": {
+			"\n",
+			nil,
+			fmt.Errorf("prompt failed nothing was selected"),
+		},
+		"one line is invalid": {
+			"0\ta\nd\n",
+			nil,
+			fmt.Errorf("prompt failed could not parse selection %q", "d"),
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			sel, err := parseSelections([]byte(tc.out), 3)
+			if !(err == nil && tc.expErr == nil || err != nil && tc.expErr != nil && err.Error() == tc.expErr.Error()) {
+				t.Errorf("Exp err %q, got %q", tc.expErr, err)
+			}
+			if !cmp.Equal(sel, tc.expSel) {
+				t.Errorf("Exp selection %v, got %v", tc.expSel, sel)
+			}
+		})
+	}
+}
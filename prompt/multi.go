@@ -0,0 +1,182 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/simontheleg/konf-go/config"
+)
+
+// MultiRunFunc describes a generic function of a prompt, which allows to
+// select multiple items. It returns the positions of all selected items.
+// Its main purpose is to be easily mockable for unit-tests
+type MultiRunFunc func(*promptui.Select) ([]int, error)
+
+// ConfirmFunc asks the user to confirm the question in label
+type ConfirmFunc func(label string) (bool, error)
+
+// TerminalMulti runs a multi-select prompt in the terminal of the user, see Multi
+var TerminalMulti = Multi(Terminal)
+
+// ConfiguredMulti returns the MultiRunFunc of the picker configured by the
+// user. If no external picker is configured, TerminalMulti is used
+func ConfiguredMulti() MultiRunFunc {
+	p := config.Picker()
+	if p.Command == "" {
+		return TerminalMulti
+	}
+	return ExternalMulti(p.Command, p.Args, TerminalMulti)
+}
+
+// Confirm asks the user a yes/no question in the terminal. Anything but a
+// yes counts as a no
+func Confirm(label string) (bool, error) {
+	p := promptui.Prompt{
+		Label:     label,
+		IsConfirm: true,
+		Stdout:    os.Stderr,
+	}
+
+	_, err := p.Run()
+	if errors.Is(err, promptui.ErrAbort) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("prompt failed %v", err)
+	}
+	return true, nil
+}
+
+// positions of the actions in the multi-select prompt
+const (
+	actionDone = iota
+	actionToggleMatching
+	numActions
+)
+
+// multiItem is a single entry of the multi-select prompt. It either wraps an
+// item of the original prompt or is one of the actions
+type multiItem struct {
+	Action   string
+	Item     interface{}
+	Selected bool
+}
+
+// Multi turns run, which can only select a single item, into a MultiRunFunc.
+//
+// promptui has no support for selecting multiple items, so run is called in a
+// loop instead. Selecting an item toggles it. Two additional entries at the
+// top of the list allow to toggle all items that match the current search
+// term and to finish the selection. Both entries are never filtered, so they
+// are always reachable. The searcher of s must not reorder its items, as the
+// selection is tracked by position
+func Multi(run RunFunc) MultiRunFunc {
+	return func(s *promptui.Select) ([]int, error) {
+		items := reflect.ValueOf(s.Items)
+		if items.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("items %v are not a slice", s.Items)
+		}
+
+		entries := make([]*multiItem, numActions, numActions+items.Len())
+		entries[actionDone] = &multiItem{}
+		entries[actionToggleMatching] = &multiItem{Action: "Toggle all items matching the search"}
+		for i := 0; i < items.Len(); i++ {
+			entries = append(entries, &multiItem{Item: items.Index(i).Interface()})
+		}
+
+		var term string
+		matches := func(input string, i int) bool {
+			return s.Searcher == nil || input == "" || s.Searcher(input, i)
+		}
+
+		ms := *s
+		ms.Items = entries
+		ms.Templates = multiTemplates(s.Templates)
+		ms.Label = strings.Repeat(" ", 4) + fmt.Sprint(s.Label)
+		ms.Searcher = func(input string, index int) bool {
+			if index == 0 {
+				term = input
+			}
+			return index < numActions || matches(input, index-numActions)
+		}
+
+		for {
+			var selected []int
+			for i, e := range entries[numActions:] {
+				if e.Selected {
+					selected = append(selected, i)
+				}
+			}
+			entries[actionDone].Action = fmt.Sprintf("Done (%d selected)", len(selected))
+
+			// every run starts without a search term, so a stale one must not be used
+			term = ""
+			pos, err := run(&ms)
+			if err != nil {
+				return nil, err
+			}
+
+			switch {
+			case pos == actionDone:
+				return selected, nil
+			case pos == actionToggleMatching:
+				toggleMatching(entries[numActions:], func(i int) bool { return matches(term, i) })
+			case pos > actionToggleMatching && pos < len(entries):
+				entries[pos].Selected = !entries[pos].Selected
+			default:
+				return nil, fmt.Errorf("invalid selection %d", pos)
+			}
+			ms.CursorPos = pos
+		}
+	}
+}
+
+// toggleMatching selects all entries that match. If all of them are already
+// selected, they are deselected instead
+func toggleMatching(entries []*multiItem, match func(i int) bool) {
+	allSelected := true
+	for i, e := range entries {
+		if match(i) && !e.Selected {
+			allSelected = false
+		}
+	}
+	for i, e := range entries {
+		if match(i) {
+			e.Selected = !allSelected
+		}
+	}
+}
+
+// multiTemplates wraps the templates of the original prompt, so they can be
+// used to render a multiItem. Selected items are marked with a checkbox
+func multiTemplates(tpls *promptui.SelectTemplates) *promptui.SelectTemplates {
+	inactive, active, details := "{{ . }}", "▸ {{ . }}", ""
+	mt := &promptui.SelectTemplates{FuncMap: promptui.FuncMap}
+	if tpls != nil {
+		if tpls.Inactive != "" {
+			inactive = tpls.Inactive
+		}
+		if tpls.Active != "" {
+			active = tpls.Active
+		}
+		if tpls.FuncMap != nil {
+			mt.FuncMap = tpls.FuncMap
+		}
+		details = tpls.Details
+	}
+
+	mt.Inactive = `{{ define "item" }}` + inactive + `{{ end }}` +
+		`{{ if .Action }}      {{ .Action }}{{ else }}{{ if .Selected }}[x]{{ else }}[ ]{{ end }} {{ template "item" .Item }}{{ end }}`
+	mt.Active = `{{ define "item" }}` + active + `{{ end }}` +
+		`{{ if .Action }}    ▸ {{ .Action }}{{ else }}{{ if .Selected }}[x]{{ else }}[ ]{{ end }} {{ template "item" .Item }}{{ end }}`
+	mt.Selected = " "
+	if details != "" {
+		mt.Details = `{{ define "item" }}` + details + `{{ end }}` +
+			`{{ if not .Action }}{{ template "item" .Item }}{{ end }}`
+	}
+	return mt
+}
@@ -0,0 +1,131 @@
+package prompt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/manifoldco/promptui"
+)
+
+// sequence returns a RunFunc, which selects the given positions one after
+// another. Strings are used as search terms before the next selection
+func sequence(steps ...interface{}) RunFunc {
+	return func(s *promptui.Select) (int, error) {
+		for len(steps) > 0 {
+			step := steps[0]
+			steps = steps[1:]
+			switch st := step.(type) {
+			case string:
+				for i := 0; i < len(s.Items.([]*multiItem)); i++ {
+					s.Searcher(st, i)
+				}
+			case int:
+				return st, nil
+			}
+		}
+		return -1, fmt.Errorf("no more steps")
+	}
+}
+
+func TestMulti(t *testing.T) {
+	items := []string{"dev-eu", "dev-asia", "prod-eu", "prod-asia"}
+	searcher := func(input string, index int) bool { return strings.Contains(items[index], input) }
+
+	tt := map[string]struct {
+		run    RunFunc
+		expSel []int
+		expErr error
+	}{
+		"nothing selected": {
+			sequence(actionDone),
+			nil,
+			nil,
+		},
+		"toggle single items": {
+			sequence(numActions+1, numActions+3, numActions+1, numActions+0, actionDone),
+			[]int{0, 3},
+			nil,
+		},
+		"toggle all matching the search": {
+			sequence("prod", actionToggleMatching, actionDone),
+			[]int{2, 3},
+			nil,
+		},
+		"toggle all without search": {
+			sequence(numActions+1, actionToggleMatching, actionDone),
+			[]int{0, 1, 2, 3},
+			nil,
+		},
+		"toggle all deselects if all are selected": {
+			sequence(numActions+2, numActions+3, "prod", actionToggleMatching, actionDone),
+			nil,
+			nil,
+		},
+		"search term does not outlive a selection": {
+			sequence("asia", numActions+1, actionToggleMatching, actionDone),
+			[]int{0, 1, 2, 3},
+			nil,
+		},
+		"prompt is cancelled": {
+			sequence(numActions + 1),
+			nil,
+			fmt.Errorf("no more steps"),
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			s := &promptui.Select{Items: items, Searcher: searcher}
+
+			sel, err := Multi(tc.run)(s)
+			if !(err == nil && tc.expErr == nil || err != nil && tc.expErr != nil && err.Error() == tc.expErr.Error()) {
+				t.Errorf("Exp err %q, got %q", tc.expErr, err)
+			}
+			if !cmp.Equal(tc.expSel, sel) {
+				t.Errorf("Exp selection %v, got %v", tc.expSel, sel)
+			}
+		})
+	}
+}
+
+func TestMultiTemplates(t *testing.T) {
+	mt := multiTemplates(&promptui.SelectTemplates{
+		Inactive: "  {{ . }}",
+		Active:   "▸ {{ . }}",
+		Details:  "details of {{ . }}",
+	})
+
+	tt := map[string]struct {
+		tpl  string
+		item *multiItem
+		exp  string
+	}{
+		"inactive item":   {mt.Inactive, &multiItem{Item: "a"}, "[ ]   a"},
+		"selected item":   {mt.Inactive, &multiItem{Item: "a", Selected: true}, "[x]   a"},
+		"active item":     {mt.Active, &multiItem{Item: "a", Selected: true}, "[x] ▸ a"},
+		"inactive action": {mt.Inactive, &multiItem{Action: "Done"}, "      Done"},
+		"active action":   {mt.Active, &multiItem{Action: "Done"}, "    ▸ Done"},
+		"item details":    {mt.Details, &multiItem{Item: "a"}, "details of a"},
+		"action details":  {mt.Details, &multiItem{Action: "Done"}, ""},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := template.New("").Funcs(mt.FuncMap).Parse(tc.tpl)
+			if err != nil {
+				t.Fatalf("Exp no error, got %v", err)
+			}
+			buf := new(bytes.Buffer)
+			if err := tmpl.Execute(buf, tc.item); err != nil {
+				t.Fatalf("Exp no error, got %v", err)
+			}
+			if buf.String() != tc.exp {
+				t.Errorf("Exp %q, got %q", tc.exp, buf.String())
+			}
+		})
+	}
+}
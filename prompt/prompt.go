@@ -2,13 +2,18 @@ package prompt
 
 import (
 	"fmt"
+	"os"
+	"slices"
+	"sort"
 	"strings"
 	"text/template"
+	"time"
 	"unicode/utf8"
 
 	"github.com/lithammer/fuzzysearch/fuzzy"
 	"github.com/manifoldco/promptui"
 	"github.com/simontheleg/konf-go/store"
+	"golang.org/x/term"
 )
 
 // RunFunc describes a generic function of a prompt. It returns the selected item.
@@ -25,52 +30,344 @@ func Terminal(prompt *promptui.Select) (sel int, err error) {
 	return pos, nil
 }
 
+// IsTerminal returns true if konf is run interactively. Only stdin is
+// checked, as stdout is always captured by the shellwrapper and prompts are
+// rendered on stderr
+func IsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
 // FuzzyFilterKonf allows fuzzy searching of a list of konf metadata in the form of store.TableOutput
 func FuzzyFilterKonf(searchTerm string, curItem *store.Metadata) bool {
-	// since there is no weight on any of the table entries, we can just combine them to one string
-	// and run the contains on it, which automatically is going to match any of the three values
-	r := fmt.Sprintf("%s %s %s", curItem.Context, curItem.Cluster, curItem.File)
-	return fuzzy.Match(searchTerm, r)
+	return ScoreKonf(searchTerm, curItem) > 0
 }
 
-// NewTableOutputTemplates returns templating strings for creating a nicely
-// formatted table out of an store.Metadata. Additionally it returns a
-// template.FuncMap with all required templating funcs for the strings. Maximum
-// length per column can be configured.
-func NewTableOutputTemplates(maxColumnLen int) (inactive, active, label string, fmap template.FuncMap) {
-	// minColumnLen is determined by the length of the largest word in the label line
-	minColumnLen := 7
-	if maxColumnLen < minColumnLen {
-		maxColumnLen = minColumnLen
+// field weights for ScoreKonf. Aliases are chosen by the user explicitly, so
+// they weigh the most
+const (
+	aliasWeight    = 4
+	contextWeight  = 3
+	clusterWeight  = 2
+	tagWeight      = 1
+	combinedWeight = 1
+)
+
+// ScoreKonf rates how well a konf matches the searchTerm. A higher score means
+// a better match and a score of 0 means no match at all.
+//
+// Each field is matched case-insensitive and rated by the Levenshtein distance
+// between the searchTerm and the field, so a field that matches exactly rates
+// higher than a field that only contains the searchTerm somewhere. The best
+// rated field determines the score. To still allow searching across fields
+// (e.g. "context cluster"), context and cluster are also matched as one
+// combined string. The file is left out on purpose, as nearly every short
+// searchTerm is a subsequence of a full path in the store
+func ScoreKonf(searchTerm string, curItem *store.Metadata) float64 {
+	var best float64
+	var rate = func(weight float64, field string) {
+		if s := scoreField(searchTerm, field) * weight; s > best {
+			best = s
+		}
+	}
+
+	for _, a := range curItem.Aliases {
+		rate(aliasWeight, a)
+	}
+	rate(contextWeight, curItem.Context)
+	rate(clusterWeight, curItem.Cluster)
+	for _, t := range curItem.Tags {
+		rate(tagWeight, t)
+	}
+	rate(combinedWeight, curItem.Context+" "+curItem.Cluster)
+
+	return best
+}
+
+// scoreField returns a score between 0 and 1 for how well a single field
+// matches the searchTerm
+func scoreField(searchTerm, field string) float64 {
+	dist := fuzzy.RankMatchFold(searchTerm, field)
+	if dist < 0 {
+		return 0
+	}
+
+	score := 1 / float64(1+dist)
+	// a coherent match is what users expect most of the time, so it should not be
+	// beaten by fields that just happen to be shorter
+	if !strings.Contains(strings.ToLower(field), strings.ToLower(searchTerm)) {
+		score /= 2
+	}
+	return score
+}
+
+// RankKonfs returns all konfs that match the searchTerm, with the best match
+// first. Konfs with the same score keep their order
+func RankKonfs(searchTerm string, konfs []*store.Metadata) []*store.Metadata {
+	scores := map[*store.Metadata]float64{}
+	ranked := []*store.Metadata{}
+	for _, k := range konfs {
+		if s := ScoreKonf(searchTerm, k); s > 0 {
+			scores[k] = s
+			ranked = append(ranked, k)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool { return scores[ranked[i]] > scores[ranked[j]] })
+	return ranked
+}
+
+// NewRankedSearcher returns a searcher for a promptui.Select over options,
+// which shows the best matches first.
+//
+// promptui can only filter its items, but never reorder them. Because options
+// are pointers, we can work around this by rearranging the values they point
+// to instead. promptui calls the searcher for every item in order, starting
+// with index 0, whenever the search term changes. So every time index 0 is
+// requested, options are rearranged according to their rank for the new term.
+// As a result, the selected index still points to the selected item in options.
+// Clearing the search term shows all items in the order of the last search
+func NewRankedSearcher(options []*store.Metadata) func(input string, index int) bool {
+	// keep a copy of the original values, so the ranking always starts from the original order
+	orig := []*store.Metadata{}
+	for _, o := range options {
+		c := *o
+		orig = append(orig, &c)
+	}
+
+	var matches int
+	return func(input string, index int) bool {
+		if index == 0 {
+			ranked := RankKonfs(input, orig)
+			matches = len(ranked)
+			for _, o := range orig {
+				if !slices.Contains(ranked, o) {
+					ranked = append(ranked, o)
+				}
+			}
+			for i, r := range ranked {
+				*options[i] = *r
+			}
+		}
+		return index < matches
+	}
+}
+
+// column describes a single column of the table in the selection prompt
+type column struct {
+	header string
+	value  func(m *store.Metadata) string
+}
+
+// columns contains a definition for every column in config.ValidColumns
+var columns = map[string]column{
+	"context":     {"Context", func(m *store.Metadata) string { return m.Context }},
+	"cluster":     {"Cluster", func(m *store.Metadata) string { return m.Cluster }},
+	"file":        {"File", func(m *store.Metadata) string { return m.File }},
+	"namespace":   {"Namespace", func(m *store.Metadata) string { return m.Namespace }},
+	"server":      {"Server", func(m *store.Metadata) string { return m.Server }},
+	"user":        {"User", func(m *store.Metadata) string { return m.User }},
+	"tags":        {"Tags", func(m *store.Metadata) string { return strings.Join(m.Tags, ",") }},
+	"description": {"Description", func(m *store.Metadata) string { return m.Description }},
+	"last-used": {"Last used", func(m *store.Metadata) string {
+		if m.LastUsed.IsZero() {
+			return "never"
+		}
+		return TimeAgo(m.LastUsed, time.Now())
+	}},
+}
+
+// gutterLen is the number of characters in front of the first column. It
+// consists of the cursor, the markers and a space
+const gutterLen = 5
+
+// TerminalWidth returns the width of the terminal the prompt is rendered in.
+// If it cannot be determined, a width of 80 is assumed
+func TerminalWidth() int {
+	w, _, err := term.GetSize(int(os.Stderr.Fd()))
+	if err != nil || w <= 0 {
+		return 80
+	}
+	return w
+}
+
+// ColumnWidths distributes termWidth across cols. Every column gets as much
+// space as the longest of its values needs. If the terminal is too narrow for
+// that, the space is shared evenly. Columns that need less than their share
+// leave the rest to the others. A column is never narrower than its header
+func ColumnWidths(cols []string, konfs []*store.Metadata, termWidth int) []int {
+	// every column is followed by " | " or " |", and we leave one character to spare, so lines never wrap
+	avail := termWidth - gutterLen - 3*len(cols)
+
+	needed := make([]int, len(cols))
+	for i, name := range cols {
+		c := columns[name]
+		needed[i] = utf8.RuneCountInString(c.header)
+		for _, k := range konfs {
+			if l := utf8.RuneCountInString(c.value(k)); l > needed[i] {
+				needed[i] = l
+			}
+		}
 	}
 
+	// hand out space to the columns that need the least first, so they can pass on what they do not need
+	order := make([]int, len(cols))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return needed[order[i]] < needed[order[j]] })
+
+	widths := make([]int, len(cols))
+	for n, i := range order {
+		share := avail / (len(cols) - n)
+		widths[i] = min(needed[i], share)
+		widths[i] = max(widths[i], utf8.RuneCountInString(columns[cols[i]].header))
+		avail -= widths[i]
+	}
+
+	return widths
+}
+
+// NewTableOutputTemplates returns templating strings for creating a nicely
+// formatted table out of an store.Metadata. Additionally it returns a
+// template.FuncMap with all required templating funcs for the strings. The
+// columns and their widths can be configured. Values that are too long for
+// their column are abbreviated using an ellipsis
+func NewTableOutputTemplates(cols []string, widths []int) (inactive, active, label string, fmap template.FuncMap) {
 	fmap = template.FuncMap{}
-	fmap["trunc"] = trunc
-	fmap["repeat"] = repeat
+	fmap["cell"] = cell
+	fmap["col"] = func(name string, m *store.Metadata) string { return columns[name].value(m) }
+	fmap["expiry"] = func(t time.Time) string { return describeExpiry(t, time.Now()) }
+	fmap["join"] = func(s []string) string { return strings.Join(s, ", ") }
 	fmap["cyan"] = promptui.Styler(promptui.FGCyan)
 	fmap["bold"] = promptui.Styler(promptui.FGBold)
 	fmap["faint"] = promptui.Styler(promptui.FGFaint) // needed to display promptui tooltip https://github.com/manifoldco/promptui/blob/v0.9.0/select.go#L473
 	fmap["green"] = promptui.Styler(promptui.FGGreen) // needed to display the successful selection https://github.com/manifoldco/promptui/blob/v0.9.0/select.go#L454
+	// protected konfs are always shown in red, so they stand out from all others
+	fmap["protected"] = func(m *store.Metadata, str string) string {
+		return styleProtected(m, str, func(v interface{}) string { return fmt.Sprint(v) })
+	}
+	fmap["highlight"] = func(m *store.Metadata, str string) string {
+		return styleProtected(m, str, promptui.Styler(promptui.FGCyan))
+	}
+
+	inactiveCells, activeCells, headers := []string{}, []string{}, []string{}
+	for i, name := range cols {
+		c, ok := columns[name]
+		if !ok {
+			continue
+		}
+
+		// a column is never narrower than its header
+		w := utf8.RuneCountInString(c.header)
+		if i < len(widths) && widths[i] > w {
+			w = widths[i]
+		}
+
+		inactiveCells = append(inactiveCells, fmt.Sprintf(`{{ col %q . | cell %d | protected . }}`, name, w))
+		activeCells = append(activeCells, fmt.Sprintf(`{{ col %q . | cell %d | bold | highlight . }}`, name, w))
+		headers = append(headers, cell(w, c.header))
+	}
 
-	// TODO figure out if we can do abbreviation using '...' somehow
-	inactive = fmt.Sprintf(`  {{ repeat %[1]d " " | print .Context | trunc %[1]d | %[2]s }} | {{ repeat %[1]d " " | print .Cluster | trunc %[1]d | %[2]s }} | {{ repeat %[1]d  " " | print .File | trunc %[1]d | %[2]s }} |`, maxColumnLen, "")
-	active = fmt.Sprintf(`▸ {{ repeat %[1]d " " | print .Context | trunc %[1]d | %[2]s }} | {{ repeat %[1]d " " | print .Cluster | trunc %[1]d | %[2]s }} | {{ repeat %[1]d  " " | print .File | trunc %[1]d | %[2]s }} |`, maxColumnLen, "bold | cyan")
-	label = fmt.Sprint("  Context" + strings.Repeat(" ", maxColumnLen-7) + " | " + "Cluster" + strings.Repeat(" ", maxColumnLen-7) + " | " + "File" + strings.Repeat(" ", maxColumnLen-4) + " ") // repeat = trunc - length of the word before it
+	// pinned konfs are marked with a star and the konf used in the current shell with an asterisk right after the cursor
+	markers := `{{ if .Pinned }}★{{ else }} {{ end }}{{ if .Active }}*{{ else }} {{ end }} `
+	inactive = "  " + markers + strings.Join(inactiveCells, " | ") + " |"
+	active = "▸ " + markers + strings.Join(activeCells, " | ") + " |"
+	label = strings.Repeat(" ", gutterLen) + strings.Join(headers, " | ") + " "
 	return inactive, active, label, fmap
 }
 
-func trunc(len int, str string) string {
-	if len <= 0 {
-		return str
+// styleProtected colors str red if m is protected. Otherwise str is styled
+// using def
+func styleProtected(m *store.Metadata, str string, def func(interface{}) string) string {
+	if m.Protected {
+		return promptui.Styler(promptui.FGRed)(str)
 	}
-	if utf8.RuneCountInString(str) < len {
+	return def(str)
+}
+
+// NewDetailsTemplate returns a templating string for showing the details of a
+// store.Metadata below the table. It requires the template.FuncMap returned
+// by NewTableOutputTemplates. Credentials are never part of a store.Metadata,
+// so they cannot end up in the details by accident
+func NewDetailsTemplate() string {
+	return `
+{{ "Server:" | faint }}      {{ .Server }}
+{{ "Auth:" | faint }}        {{ .AuthMethod }}
+{{ "Namespace:" | faint }}   {{ or .Namespace "default" }}
+{{ "Expires:" | faint }}     {{ expiry .CredentialExpiry }}
+{{- if .Tags }}
+{{ "Tags:" | faint }}        {{ join .Tags }}
+{{- end }}
+{{- if .Description }}
+{{ "Description:" | faint }} {{ .Description }}
+{{- end }}`
+}
+
+// describeExpiry describes when credentials expire relative to now
+func describeExpiry(t time.Time, now time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+
+	date := t.Local().Format("2006-01-02 15:04")
+	if !t.After(now) {
+		return fmt.Sprintf("expired %s ago (%s)", shortDuration(now.Sub(t)), date)
+	}
+	return fmt.Sprintf("in %s (%s)", shortDuration(t.Sub(now)), date)
+}
+
+// cell abbreviates str to fit into width and pads it with spaces, so all
+// cells of a column line up
+func cell(width int, str string) string {
+	str = abbrev(width, str)
+	return str + strings.Repeat(" ", width-utf8.RuneCountInString(str))
+}
+
+// abbrev shortens str to len characters. A shortened string ends with an
+// ellipsis, so the user knows there is more to it
+func abbrev(len int, str string) string {
+	if len <= 0 || utf8.RuneCountInString(str) <= len {
 		return str
 	}
 
-	return string([]rune(str)[:len])
+	return string([]rune(str)[:len-1]) + "…"
 }
 
-func repeat(count int, str string) string {
-	return strings.Repeat(str, count)
+// TimeAgo returns a short human readable description of how long ago t was
+func TimeAgo(t time.Time, now time.Time) string {
+	// konfs that have been migrated from the latest konf file have no timestamp
+	if t.IsZero() {
+		return "unknown"
+	}
+
+	d := now.Sub(t)
+	if d < time.Minute {
+		return "just now"
+	}
+	return shortDuration(d) + " ago"
+}
+
+// TimeLeft returns a short human readable description of how much time is
+// left until t
+func TimeLeft(t time.Time, now time.Time) string {
+	d := t.Sub(now)
+	if d <= 0 {
+		return "expired"
+	}
+	if d < time.Minute {
+		return "less than a minute"
+	}
+	return shortDuration(d)
 }
 
+// shortDuration formats d using only its largest unit, e.g. 5m, 3h or 2d
+func shortDuration(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
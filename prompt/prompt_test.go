@@ -5,7 +5,10 @@ import (
 	"strings"
 	"testing"
 	"text/template"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/simontheleg/konf-go/config"
 	"github.com/simontheleg/konf-go/store"
 )
 
@@ -16,15 +19,20 @@ func TestFuzzyFilterKonf(t *testing.T) {
 		expRes bool
 	}{
 		"full match across all": {
-			"a b c",
+			"a b",
 			&store.Metadata{Context: "a", Cluster: "b", File: "c"},
 			true,
 		},
 		"full match across all - fuzzy": {
-			"abc",
+			"ab",
 			&store.Metadata{Context: "a", Cluster: "b", File: "c"},
 			true,
 		},
+		"match in path only": {
+			"store",
+			&store.Metadata{Context: "dev-eu", Cluster: "dev-eu-1", File: "/home/u/.kube/konfs/store/dev-eu_dev-eu-1.yaml"},
+			false,
+		},
 		"partial match across fields": {
 			"textclu",
 			&store.Metadata{Context: "context", Cluster: "cluster", File: "file"},
@@ -47,63 +55,189 @@ func TestFuzzyFilterKonf(t *testing.T) {
 	}
 }
 
+func TestRankKonfs(t *testing.T) {
+	konfs := []*store.Metadata{
+		{Context: "dev-eu", Cluster: "dev-eu-1"},
+		{Context: "prod", Cluster: "prod-eu-1", Tags: []string{"production"}},
+		{Context: "p-r-o-d", Cluster: "other"},
+		{Context: "staging", Cluster: "staging-eu-1", Aliases: []string{"prd"}},
+	}
+
+	tt := map[string]struct {
+		search string
+		exp    []string
+	}{
+		"exact context before coherent match before scattered match": {
+			"prod",
+			[]string{"prod", "p-r-o-d"},
+		},
+		"alias weighs more than fuzzy context match": {
+			"prd",
+			[]string{"staging", "prod", "p-r-o-d"},
+		},
+		"case insensitive": {
+			"DEV",
+			[]string{"dev-eu"},
+		},
+		"search across fields": {
+			"dev-eu dev-eu-1",
+			[]string{"dev-eu"},
+		},
+		"no match": {
+			"oranges",
+			[]string{},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			res := []string{}
+			for _, k := range RankKonfs(tc.search, konfs) {
+				res = append(res, k.Context)
+			}
+			if !cmp.Equal(res, tc.exp) {
+				t.Errorf("Exp order %v, got %v", tc.exp, res)
+			}
+		})
+	}
+}
+
+func TestNewRankedSearcher(t *testing.T) {
+	options := []*store.Metadata{
+		{Context: "p-r-o-d", Cluster: "other"},
+		{Context: "dev-eu", Cluster: "dev-eu-1"},
+		{Context: "prod", Cluster: "prod-eu-1"},
+	}
+	searcher := NewRankedSearcher(options)
+
+	// simulate promptui, which calls the searcher for every item in order
+	search := func(input string) []string {
+		res := []string{}
+		for i := range options {
+			if searcher(input, i) {
+				res = append(res, options[i].Context)
+			}
+		}
+		return res
+	}
+
+	if res, exp := search("prod"), []string{"prod", "p-r-o-d"}; !cmp.Equal(res, exp) {
+		t.Errorf("Exp matches %v, got %v", exp, res)
+	}
+	// options must be rearranged as well, so the selected index points to the right konf
+	if options[0].Context != "prod" || options[0].Cluster != "prod-eu-1" {
+		t.Errorf("Exp first option to be %q, got %v", "prod", options[0])
+	}
+
+	if res, exp := search("dev"), []string{"dev-eu"}; !cmp.Equal(res, exp) {
+		t.Errorf("Exp matches %v, got %v", exp, res)
+	}
+}
+
 func TestPrepareTemplates(t *testing.T) {
+	defaultCols := []string{"context", "cluster", "file"}
+
 	tt := map[string]struct {
 		Values      store.Metadata
-		Trunc       int
+		Cols        []string
+		Widths      []int
 		ExpInactive string
 		ExpActive   string
 		ExpLabel    string
 	}{
-		"values < trunc": {
+		"values < width": {
 			store.Metadata{
 				Context: "kind-eu",
 				Cluster: "cluster-eu",
 				File:    "kind-eu.cluster-eu.yaml",
 			},
-			25,
-			"  kind-eu                   | cluster-eu                | kind-eu.cluster-eu.yaml   |",
-			"▸ kind-eu                   | cluster-eu                | kind-eu.cluster-eu.yaml   |",
-			"  Context                   | Cluster                   | File                      ",
+			defaultCols,
+			[]int{25, 25, 25},
+			"     kind-eu                   | cluster-eu                | kind-eu.cluster-eu.yaml   |",
+			"▸    kind-eu                   | cluster-eu                | kind-eu.cluster-eu.yaml   |",
+			"     Context                   | Cluster                   | File                      ",
 		},
-		"values == trunc": {
+		"values == width": {
 			store.Metadata{
 				Context: "0123456789",
 				Cluster: "0123456789",
 				File:    "xyz.yaml",
 			},
-			10,
-			"  0123456789 | 0123456789 | xyz.yaml   |",
-			"▸ 0123456789 | 0123456789 | xyz.yaml   |",
-			"  Context    | Cluster    | File       ",
+			defaultCols,
+			[]int{10, 10, 10},
+			"     0123456789 | 0123456789 | xyz.yaml   |",
+			"▸    0123456789 | 0123456789 | xyz.yaml   |",
+			"     Context    | Cluster    | File       ",
 		},
-		"values > trunc": {
+		"values > width": {
 			store.Metadata{
 				Context: "0123456789-andlotsmore",
 				Cluster: "0123456789-andlotsmore",
 				File:    "xyz.yaml",
 			},
-			10,
-			"  0123456789 | 0123456789 | xyz.yaml   |",
-			"▸ 0123456789 | 0123456789 | xyz.yaml   |",
-			"  Context    | Cluster    | File       ",
+			defaultCols,
+			[]int{10, 10, 10},
+			"     012345678… | 012345678… | xyz.yaml   |",
+			"▸    012345678… | 012345678… | xyz.yaml   |",
+			"     Context    | Cluster    | File       ",
+		},
+		"konf is active": {
+			store.Metadata{
+				Context: "kind-eu",
+				Cluster: "cluster-eu",
+				File:    "xyz.yaml",
+				Active:  true,
+			},
+			defaultCols,
+			[]int{10, 10, 10},
+			"   * kind-eu    | cluster-eu | xyz.yaml   |",
+			"▸  * kind-eu    | cluster-eu | xyz.yaml   |",
+			"     Context    | Cluster    | File       ",
+		},
+		"konf is pinned and active": {
+			store.Metadata{
+				Context: "kind-eu",
+				Cluster: "cluster-eu",
+				File:    "xyz.yaml",
+				Active:  true,
+				Pinned:  true,
+			},
+			defaultCols,
+			[]int{10, 10, 10},
+			"  ★* kind-eu    | cluster-eu | xyz.yaml   |",
+			"▸ ★* kind-eu    | cluster-eu | xyz.yaml   |",
+			"     Context    | Cluster    | File       ",
 		},
-		"trunc is below minLength": {
+		"width is below header length": {
 			store.Metadata{
 				Context: "0123456789",
 				Cluster: "0123456789",
 				File:    "xyz.yaml",
 			},
-			5,
-			"  0123456 | 0123456 | xyz.yam |",
-			"▸ 0123456 | 0123456 | xyz.yam |",
-			"  Context | Cluster | File    ",
+			defaultCols,
+			[]int{5, 5, 5},
+			"     012345… | 012345… | xyz.… |",
+			"▸    012345… | 012345… | xyz.… |",
+			"     Context | Cluster | File  ",
+		},
+		"custom columns": {
+			store.Metadata{
+				Context:     "kind-eu",
+				Namespace:   "kube-system",
+				Tags:        []string{"dev", "eu"},
+				Description: "local cluster",
+			},
+			[]string{"namespace", "context", "tags", "last-used"},
+			[]int{11, 7, 6, 9},
+			"     kube-system | kind-eu | dev,eu | never     |",
+			"▸    kube-system | kind-eu | dev,eu | never     |",
+			"     Namespace   | Context | Tags   | Last used ",
 		},
 	}
 
 	for name, tc := range tt {
 		t.Run(name, func(t *testing.T) {
-			inactive, active, label, fmap := NewTableOutputTemplates(tc.Trunc)
+			inactive, active, label, fmap := NewTableOutputTemplates(tc.Cols, tc.Widths)
 
 			checkTemplate(t, inactive, tc.Values, tc.ExpInactive, fmap)
 			checkTemplate(t, active, tc.Values, tc.ExpActive, fmap)
@@ -112,6 +246,26 @@ func TestPrepareTemplates(t *testing.T) {
 	}
 }
 
+func TestProtectedKonfsAreRed(t *testing.T) {
+	red := "\x1b[31m"
+	inactive, active, _, fmap := NewTableOutputTemplates([]string{"context"}, []int{10})
+
+	for name, protected := range map[string]bool{"protected": true, "not protected": false} {
+		t.Run(name, func(t *testing.T) {
+			for _, stpl := range []string{inactive, active} {
+				buf := new(bytes.Buffer)
+				tmpl := template.Must(template.New("t").Funcs(fmap).Parse(stpl))
+				if err := tmpl.Execute(buf, &store.Metadata{Context: "prod", Protected: protected}); err != nil {
+					t.Fatalf("Could not execute template: %v", err)
+				}
+				if res := strings.Contains(buf.String(), red); res != protected {
+					t.Errorf("Exp %q to be red: %t, got %t", buf.String(), protected, res)
+				}
+			}
+		})
+	}
+}
+
 func checkTemplate(t *testing.T, stpl string, val store.Metadata, exp string, fmap template.FuncMap) {
 
 	tmpl, err := template.New("t").Funcs(fmap).Parse(stpl)
@@ -120,7 +274,8 @@ func checkTemplate(t *testing.T, stpl string, val store.Metadata, exp string, fm
 	}
 
 	buf := new(bytes.Buffer)
-	err = tmpl.Execute(buf, val)
+	// promptui passes the items as they are, which are pointers in our case
+	err = tmpl.Execute(buf, &val)
 	if err != nil {
 		t.Fatalf("Could not execute template for test '%v'. Please check test code", err)
 	}
@@ -138,41 +293,208 @@ func checkTemplate(t *testing.T, stpl string, val store.Metadata, exp string, fm
 	}
 }
 
-func TestTrunc(t *testing.T) {
+func TestDetailsTemplate(t *testing.T) {
+	_, _, _, fmap := NewTableOutputTemplates([]string{"context"}, []int{10})
+
+	tt := map[string]struct {
+		Values store.Metadata
+		Exp    string
+	}{
+		"all details": {
+			store.Metadata{
+				Server:      "https://10.1.1.0",
+				AuthMethod:  "exec plugin (aws)",
+				Namespace:   "kube-system",
+				Tags:        []string{"eu", "production"},
+				Description: "main cluster",
+			},
+			`
+Server:      https://10.1.1.0
+Auth:        exec plugin (aws)
+Namespace:   kube-system
+Expires:     unknown
+Tags:        eu, production
+Description: main cluster`,
+		},
+		"minimal details": {
+			store.Metadata{
+				Server:     "https://10.1.1.0",
+				AuthMethod: "token",
+			},
+			`
+Server:      https://10.1.1.0
+Auth:        token
+Namespace:   default
+Expires:     unknown`,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := template.New("t").Funcs(fmap).Parse(NewDetailsTemplate())
+			if err != nil {
+				t.Fatalf("Could not create template: %v", err)
+			}
+
+			buf := new(bytes.Buffer)
+			if err := tmpl.Execute(buf, &tc.Values); err != nil {
+				t.Fatalf("Could not execute template: %v", err)
+			}
+
+			// remove any formatting as we do not care about that
+			res := strings.NewReplacer("\x1b[2m", "", "\x1b[0m", "").Replace(buf.String())
+			if res != tc.Exp {
+				t.Errorf("Exp details:\n%s\ngot:\n%s", tc.Exp, res)
+			}
+		})
+	}
+}
+
+func TestDescribeExpiry(t *testing.T) {
+	now := time.Date(2022, 1, 10, 12, 0, 0, 0, time.Local)
+
+	tt := map[string]struct {
+		t   time.Time
+		exp string
+	}{
+		"unknown": {time.Time{}, "unknown"},
+		"expired": {now.Add(-50 * time.Hour), "expired 2d ago (2022-01-08 10:00)"},
+		"valid":   {now.Add(3 * time.Hour), "in 3h (2022-01-10 15:00)"},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if res := describeExpiry(tc.t, now); res != tc.exp {
+				t.Errorf("Exp %q, got %q", tc.exp, res)
+			}
+		})
+	}
+}
+
+func TestAllColumnsAreDefined(t *testing.T) {
+	for _, c := range config.ValidColumns {
+		if _, ok := columns[c]; !ok {
+			t.Errorf("Exp column %q to be defined, but it is not", c)
+		}
+	}
+}
+
+func TestColumnWidths(t *testing.T) {
+	konfs := []*store.Metadata{
+		{Context: "kind-eu", Cluster: "a-very-long-cluster-name-in-europe", File: "./konf/store/kind-eu_a-very-long-cluster-name-in-europe.yaml"},
+		{Context: "kind-asia", Cluster: "asia", File: "./konf/store/kind-asia_asia.yaml"},
+	}
+	cols := []string{"context", "cluster", "file"}
+
+	tt := map[string]struct {
+		termWidth int
+		exp       []int
+	}{
+		"wide terminal fits all values": {
+			200,
+			[]int{9, 34, 60},
+		},
+		"narrow terminal shares width evenly": {
+			// 80 - gutter - separators = 66 available
+			80,
+			[]int{9, 28, 29},
+		},
+		"terminal too narrow for headers": {
+			20,
+			[]int{7, 7, 4},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			res := ColumnWidths(cols, konfs, tc.termWidth)
+			if !cmp.Equal(res, tc.exp) {
+				t.Errorf("Exp widths %v, got %v", tc.exp, res)
+			}
+		})
+	}
+}
+
+func TestAbbrev(t *testing.T) {
 	tt := []struct {
 		str string
 		len int
 		exp string
 	}{
-		{"12345678", 4, "1234"},
+		{"12345678", 4, "123…"},
+		{"1234", 4, "1234"},
 		{"12345678", 0, "12345678"},
-		{"お前はもう死んでいる-何", 10, "お前はもう死んでいる"},
+		{"お前はもう死んでいる-何", 10, "お前はもう死んでい…"},
 	}
 
 	for _, tc := range tt {
-		res := trunc(tc.len, tc.str)
+		res := abbrev(tc.len, tc.str)
 		if res != tc.exp {
 			t.Errorf("Expected string %q, got %q", tc.exp, res)
 		}
 	}
 }
 
-// This is of course slightly silly, since we just use standard strings.Repeat
-// in our func, but the things we do for coverage ;)
-func TestRepeat(t *testing.T) {
+func TestCell(t *testing.T) {
 	tt := []struct {
 		str   string
-		count int
+		width int
 		exp   string
 	}{
-		{"1", 4, "1111"},
-		{"1 2", 5, "1 21 21 21 21 2"},
+		{"abc", 5, "abc  "},
+		{"abcdef", 5, "abcd…"},
+		{"", 3, "   "},
 	}
 
 	for _, tc := range tt {
-		res := repeat(tc.count, tc.str)
+		res := cell(tc.width, tc.str)
 		if res != tc.exp {
 			t.Errorf("Expected string %q, got %q", tc.exp, res)
 		}
 	}
 }
+
+func TestTimeAgo(t *testing.T) {
+	now := time.Date(2022, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	tt := map[string]struct {
+		t   time.Time
+		exp string
+	}{
+		"unknown": {time.Time{}, "unknown"},
+		"seconds": {now.Add(-30 * time.Second), "just now"},
+		"minutes": {now.Add(-5 * time.Minute), "5m ago"},
+		"hours":   {now.Add(-3 * time.Hour), "3h ago"},
+		"days":    {now.Add(-50 * time.Hour), "2d ago"},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if res := TimeAgo(tc.t, now); res != tc.exp {
+				t.Errorf("Exp %q, got %q", tc.exp, res)
+			}
+		})
+	}
+}
+
+func TestTimeLeft(t *testing.T) {
+	now := time.Date(2022, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	tt := map[string]struct {
+		t   time.Time
+		exp string
+	}{
+		"expired": {now.Add(-time.Minute), "expired"},
+		"seconds": {now.Add(30 * time.Second), "less than a minute"},
+		"minutes": {now.Add(42 * time.Minute), "42m"},
+		"hours":   {now.Add(3 * time.Hour), "3h"},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if res := TimeLeft(tc.t, now); res != tc.exp {
+				t.Errorf("Exp %q, got %q", tc.exp, res)
+			}
+		})
+	}
+}
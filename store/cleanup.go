@@ -0,0 +1,26 @@
+package store
+
+import "time"
+
+const cleanupState = "cleanup"
+
+// cleanupInfo is the state of the automatic cleanup of leftover active konfs
+type cleanupInfo struct {
+	Last time.Time `json:"last"`
+}
+
+// LastCleanup returns when leftover active konfs have last been cleaned up
+// automatically. If that never happened, the zero time is returned
+func (s *Storemanager) LastCleanup() (time.Time, error) {
+	info := cleanupInfo{}
+	if err := s.readState(cleanupState, &info); err != nil {
+		return time.Time{}, err
+	}
+
+	return info.Last, nil
+}
+
+// SetLastCleanup records t as the time of the last automatic cleanup
+func (s *Storemanager) SetLastCleanup(t time.Time) error {
+	return s.writeState(cleanupState, cleanupInfo{Last: t})
+}
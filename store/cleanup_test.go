@@ -0,0 +1,32 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+func TestLastCleanup(t *testing.T) {
+	sm := &Storemanager{Fs: afero.NewMemMapFs(), Statedir: "./konf/state"}
+
+	last, err := sm.LastCleanup()
+	if err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	if !last.IsZero() {
+		t.Errorf("Exp no cleanup to have happened yet, got %v", last)
+	}
+
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	if err := sm.SetLastCleanup(now); err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	last, err = sm.LastCleanup()
+	if err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	if !last.Equal(now) {
+		t.Errorf("Exp last cleanup to be %v, got %v", now, last)
+	}
+}
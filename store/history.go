@@ -0,0 +1,112 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/utils"
+	"github.com/spf13/afero"
+)
+
+// MaxHistory is the maximum number of konf switches that are remembered
+const MaxHistory = 100
+
+// HistoryEntry describes a single switch to a konf
+type HistoryEntry struct {
+	ID   konf.KonfID
+	Time time.Time
+}
+
+func (s *Storemanager) historyPath() string {
+	return s.Statedir + "/history"
+}
+
+// AddToHistory records a switch to the konf with the supplied id.
+//
+// Because multiple shells can switch konfs at the same time, the history is
+// not a yaml file like the other state. Instead each switch is appended as a
+// single line using O_APPEND, which is safe for concurrent writers. Once the
+// file grows too large, it is compacted to the MaxHistory most recent entries
+func (s *Storemanager) AddToHistory(id konf.KonfID, t time.Time) error {
+	if err := s.Fs.MkdirAll(s.Statedir, utils.KonfDirPerm); err != nil {
+		return err
+	}
+
+	f, err := s.Fs.OpenFile(s.historyPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, utils.KonfPerm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write([]byte(t.UTC().Format(time.RFC3339) + "\t" + string(id) + "\n"))
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	lines, err := s.historyLines()
+	if err != nil {
+		return err
+	}
+	// we allow the file to grow a bit beyond its limit, so we do not need to
+	// rewrite it on every single switch
+	if len(lines) <= 2*MaxHistory {
+		return nil
+	}
+
+	// In the unlikely case that another shell appends in between reading and
+	// writing, its entry is lost. This is acceptable, as it will never corrupt
+	// the file
+	compacted := strings.Join(lines[len(lines)-MaxHistory:], "\n") + "\n"
+	return utils.WriteFileAtomic(s.Fs, s.historyPath(), []byte(compacted), utils.KonfPerm)
+}
+
+// History returns the most recent konf switches, with the most recent one
+// first. Lines which cannot be parsed are skipped
+func (s *Storemanager) History() ([]*HistoryEntry, error) {
+	lines, err := s.historyLines()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []*HistoryEntry{}
+	for i := len(lines) - 1; i >= 0 && len(entries) < MaxHistory; i-- {
+		ts, id, found := strings.Cut(lines[i], "\t")
+		if !found || id == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, ts)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, &HistoryEntry{ID: konf.KonfID(id), Time: t})
+	}
+
+	return entries, nil
+}
+
+func (s *Storemanager) historyLines() ([]string, error) {
+	b, err := afero.ReadFile(s.Fs, s.historyPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []string{}
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		if sc.Text() != "" {
+			lines = append(lines, sc.Text())
+		}
+	}
+	return lines, sc.Err()
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/utils"
+	"github.com/spf13/afero"
+)
+
+func TestHistory(t *testing.T) {
+	sm := &Storemanager{Fs: afero.NewMemMapFs(), Statedir: "./konf/state"}
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	entries, err := sm.History()
+	if err != nil {
+		t.Fatalf("Exp no error for missing history, but got %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Exp empty history, got %d entries", len(entries))
+	}
+
+	ids := []konf.KonfID{"dev-eu_dev-eu-1", "dev-asia_dev-asia-1", "dev-eu_dev-eu-1"}
+	for i, id := range ids {
+		if err := sm.AddToHistory(id, start.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("Exp no error, but got %v", err)
+		}
+	}
+
+	// a corrupt line should not break the history
+	f, _ := sm.Fs.OpenFile(sm.historyPath(), os.O_WRONLY|os.O_APPEND, utils.KonfPerm)
+	f.Write([]byte("i am not a valid line\n"))
+	f.Close()
+
+	entries, err = sm.History()
+	if err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	if len(entries) != len(ids) {
+		t.Fatalf("Exp %d entries, got %d", len(ids), len(entries))
+	}
+	for i, e := range entries {
+		expID := ids[len(ids)-1-i]
+		expTime := start.Add(time.Duration(len(ids)-1-i) * time.Minute)
+		if e.ID != expID {
+			t.Errorf("Exp entry %d to have id %q, got %q", i, expID, e.ID)
+		}
+		if !e.Time.Equal(expTime) {
+			t.Errorf("Exp entry %d to have time %v, got %v", i, expTime, e.Time)
+		}
+	}
+}
+
+func TestHistoryIsBounded(t *testing.T) {
+	sm := &Storemanager{Fs: afero.NewMemMapFs(), Statedir: "./konf/state"}
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2*MaxHistory+1; i++ {
+		if err := sm.AddToHistory("dev-eu_dev-eu-1", start.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatalf("Exp no error, but got %v", err)
+		}
+	}
+
+	lines, err := sm.historyLines()
+	if err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	if len(lines) != MaxHistory {
+		t.Errorf("Exp history file to be compacted to %d lines, got %d", MaxHistory, len(lines))
+	}
+
+	entries, err := sm.History()
+	if err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	if exp := start.Add(2 * MaxHistory * time.Second); !entries[0].Time.Equal(exp) {
+		t.Errorf("Exp most recent entry to be kept, got %v", entries[0].Time)
+	}
+}
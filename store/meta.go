@@ -0,0 +1,82 @@
+package store
+
+import (
+	"slices"
+
+	"github.com/simontheleg/konf-go/konf"
+)
+
+const metaState = "meta"
+
+// KonfMeta describes additional information about a konf, which is not part of
+// its kubeconfig. It is kept in konf's own state, so it survives re-importing
+// a kubeconfig and never ends up in active konfs
+type KonfMeta struct {
+	Aliases     []string `json:"aliases,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Description string   `json:"description,omitempty"`
+	// TTL is how long a session using the konf stays valid, e.g. 60m. Sessions
+	// of konfs without a TTL never expire
+	TTL string `json:"ttl,omitempty"`
+	// Protected konfs are highlighted and switching to them has to be confirmed
+	Protected bool `json:"protected,omitempty"`
+}
+
+// IsEmpty returns true if no metadata has been set
+func (m *KonfMeta) IsEmpty() bool {
+	return m == nil || len(m.Aliases) == 0 && len(m.Tags) == 0 && m.Description == "" && m.TTL == "" && !m.Protected
+}
+
+// Metas returns the metadata of all konfs that have any metadata set
+func (s *Storemanager) Metas() (map[konf.KonfID]*KonfMeta, error) {
+	metas := map[konf.KonfID]*KonfMeta{}
+	if err := s.readState(metaState, &metas); err != nil {
+		return nil, err
+	}
+
+	return metas, nil
+}
+
+// Meta returns the metadata of the konf with the supplied id. If no metadata
+// has been set, an empty KonfMeta is returned
+func (s *Storemanager) Meta(id konf.KonfID) (*KonfMeta, error) {
+	metas, err := s.Metas()
+	if err != nil {
+		return nil, err
+	}
+
+	if m, ok := metas[id]; ok {
+		return m, nil
+	}
+	return &KonfMeta{}, nil
+}
+
+// SetMeta replaces the metadata of the konf with the supplied id. Setting empty
+// metadata removes the konf from the state altogether
+func (s *Storemanager) SetMeta(id konf.KonfID, m *KonfMeta) error {
+	metas := map[konf.KonfID]*KonfMeta{}
+	return s.updateState(metaState, &metas, func() error {
+		if m.IsEmpty() {
+			delete(metas, id)
+		} else {
+			metas[id] = m
+		}
+		return nil
+	})
+}
+
+// IDForAlias returns the ID of the konf that has the supplied alias. If no konf
+// has the alias, found is false
+func (s *Storemanager) IDForAlias(alias string) (id konf.KonfID, found bool, err error) {
+	metas, err := s.Metas()
+	if err != nil {
+		return "", false, err
+	}
+
+	for id, m := range metas {
+		if slices.Contains(m.Aliases, alias) {
+			return id, true, nil
+		}
+	}
+	return "", false, nil
+}
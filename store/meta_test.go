@@ -0,0 +1,50 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/spf13/afero"
+)
+
+func TestMeta(t *testing.T) {
+	sm := &Storemanager{Fs: afero.NewMemMapFs(), Statedir: "./konf/state"}
+	eu := konf.KonfID("dev-eu_dev-eu-1")
+	asia := konf.KonfID("dev-asia_dev-asia-1")
+
+	if err := sm.SetMeta(eu, &KonfMeta{Aliases: []string{"eu"}, Tags: []string{"dev"}}); err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	if err := sm.SetMeta(asia, &KonfMeta{Description: "asia dev cluster"}); err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+
+	m, err := sm.Meta(eu)
+	if err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	if exp := (&KonfMeta{Aliases: []string{"eu"}, Tags: []string{"dev"}}); !cmp.Equal(m, exp) {
+		t.Errorf("Exp meta %v, got %v", exp, m)
+	}
+
+	id, found, err := sm.IDForAlias("eu")
+	if err != nil || !found || id != eu {
+		t.Errorf("Exp alias %q to resolve to %q, got %q (found: %t, err: %v)", "eu", eu, id, found, err)
+	}
+	if _, found, _ := sm.IDForAlias("asia"); found {
+		t.Errorf("Exp alias %q to not be found", "asia")
+	}
+
+	// setting empty metadata removes the konf from the state
+	if err := sm.SetMeta(asia, &KonfMeta{}); err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	metas, err := sm.Metas()
+	if err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	if _, ok := metas[asia]; ok || len(metas) != 1 {
+		t.Errorf("Exp only %q to have metadata, got %v", eu, metas)
+	}
+}
@@ -0,0 +1,58 @@
+package store
+
+import (
+	"github.com/simontheleg/konf-go/konf"
+)
+
+// MaxNamespaceHistory is the maximum number of namespaces remembered per konf
+const MaxNamespaceHistory = 10
+
+const namespaceHistoryState = "namespaces"
+
+// NamespaceHistory returns the recently used namespaces of a konf. The most
+// recently used namespace comes first
+func (s *Storemanager) NamespaceHistory(id konf.KonfID) ([]string, error) {
+	hist := map[konf.KonfID][]string{}
+	if err := s.readState(namespaceHistoryState, &hist); err != nil {
+		return nil, err
+	}
+
+	if hist[id] == nil {
+		return []string{}, nil
+	}
+	return hist[id], nil
+}
+
+// AddToNamespaceHistory adds namespaces to the history of a konf. They are
+// added in the order supplied, meaning the last one becomes the most recent.
+// Empty namespaces are ignored and every namespace is only remembered once
+func (s *Storemanager) AddToNamespaceHistory(id konf.KonfID, nss ...string) error {
+	hist := map[konf.KonfID][]string{}
+	return s.updateState(namespaceHistoryState, &hist, func() error {
+		hist[id] = addToNamespaceHistory(hist[id], nss...)
+		return nil
+	})
+}
+
+// addToNamespaceHistory adds namespaces to the supplied history and returns
+// the result. See AddToNamespaceHistory
+func addToNamespaceHistory(cur []string, nss ...string) []string {
+	for _, ns := range nss {
+		if ns == "" {
+			continue
+		}
+
+		updated := []string{ns}
+		for _, c := range cur {
+			if c != ns {
+				updated = append(updated, c)
+			}
+		}
+		cur = updated
+	}
+
+	if len(cur) > MaxNamespaceHistory {
+		cur = cur[:MaxNamespaceHistory]
+	}
+	return cur
+}
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/spf13/afero"
+)
+
+func TestAddToNamespaceHistory(t *testing.T) {
+	tt := map[string]struct {
+		existing []string
+		add      []string
+		exp      []string
+	}{
+		"empty history": {
+			nil,
+			[]string{"kube-public", "kube-system"},
+			[]string{"kube-system", "kube-public"},
+		},
+		"namespace already in history": {
+			[]string{"default", "kube-system", "kube-public"},
+			[]string{"kube-public"},
+			[]string{"kube-public", "default", "kube-system"},
+		},
+		"empty namespaces are ignored": {
+			[]string{"default"},
+			[]string{"", "kube-system"},
+			[]string{"kube-system", "default"},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			sm := &Storemanager{Fs: afero.NewMemMapFs(), Statedir: "./konf/state"}
+			id := konf.KonfID("dev-eu_dev-eu-1")
+
+			if tc.existing != nil {
+				// history is saved oldest first
+				for i := len(tc.existing) - 1; i >= 0; i-- {
+					if err := sm.AddToNamespaceHistory(id, tc.existing[i]); err != nil {
+						t.Fatalf("Could not create history, please check test code: %v", err)
+					}
+				}
+			}
+
+			if err := sm.AddToNamespaceHistory(id, tc.add...); err != nil {
+				t.Fatalf("Exp no error, but got %v", err)
+			}
+
+			res, err := sm.NamespaceHistory(id)
+			if err != nil {
+				t.Fatalf("Exp no error, but got %v", err)
+			}
+			if !cmp.Equal(res, tc.exp) {
+				t.Errorf("Exp history %v, got %v", tc.exp, res)
+			}
+
+			// histories of other konfs are independent
+			other, err := sm.NamespaceHistory("dev-asia_dev-asia-1")
+			if err != nil {
+				t.Fatalf("Exp no error, but got %v", err)
+			}
+			if len(other) != 0 {
+				t.Errorf("Exp history of other konf to be empty, got %v", other)
+			}
+		})
+	}
+}
+
+func TestNamespaceHistoryIsBounded(t *testing.T) {
+	sm := &Storemanager{Fs: afero.NewMemMapFs(), Statedir: "./konf/state"}
+	id := konf.KonfID("dev-eu_dev-eu-1")
+
+	for i := 0; i < MaxNamespaceHistory+5; i++ {
+		if err := sm.AddToNamespaceHistory(id, fmt.Sprintf("ns-%d", i)); err != nil {
+			t.Fatalf("Exp no error, but got %v", err)
+		}
+	}
+
+	res, err := sm.NamespaceHistory(id)
+	if err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	if len(res) != MaxNamespaceHistory {
+		t.Errorf("Exp history to contain %d entries, got %d", MaxNamespaceHistory, len(res))
+	}
+	if res[0] != fmt.Sprintf("ns-%d", MaxNamespaceHistory+4) {
+		t.Errorf("Exp most recent namespace first, got %q", res[0])
+	}
+}
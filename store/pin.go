@@ -0,0 +1,49 @@
+package store
+
+import (
+	"slices"
+
+	"github.com/simontheleg/konf-go/konf"
+)
+
+const pinState = "pins"
+
+// Pins returns the IDs of all pinned konfs in the order they have been pinned
+func (s *Storemanager) Pins() ([]konf.KonfID, error) {
+	pins := []konf.KonfID{}
+	if err := s.readState(pinState, &pins); err != nil {
+		return nil, err
+	}
+
+	return pins, nil
+}
+
+// Pin adds the konf with the supplied id to the end of the pinned konfs. It
+// returns false if the konf was already pinned
+func (s *Storemanager) Pin(id konf.KonfID) (bool, error) {
+	pins := []konf.KonfID{}
+	pinned := false
+	err := s.updateState(pinState, &pins, func() error {
+		if !slices.Contains(pins, id) {
+			pins = append(pins, id)
+			pinned = true
+		}
+		return nil
+	})
+	return pinned, err
+}
+
+// Unpin removes the konf with the supplied id from the pinned konfs. It
+// returns false if the konf was not pinned
+func (s *Storemanager) Unpin(id konf.KonfID) (bool, error) {
+	pins := []konf.KonfID{}
+	unpinned := false
+	err := s.updateState(pinState, &pins, func() error {
+		if i := slices.Index(pins, id); i >= 0 {
+			pins = slices.Delete(pins, i, i+1)
+			unpinned = true
+		}
+		return nil
+	})
+	return unpinned, err
+}
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/spf13/afero"
+)
+
+func TestPinAndUnpin(t *testing.T) {
+	sm := &Storemanager{Fs: afero.NewMemMapFs(), Statedir: "./konf/state"}
+
+	steps := []struct {
+		pin        bool
+		id         konf.KonfID
+		expChanged bool
+		expPins    []konf.KonfID
+	}{
+		{true, "dev-eu_dev-eu-1", true, []konf.KonfID{"dev-eu_dev-eu-1"}},
+		{true, "dev-asia_dev-asia-1", true, []konf.KonfID{"dev-eu_dev-eu-1", "dev-asia_dev-asia-1"}},
+		{true, "dev-eu_dev-eu-1", false, []konf.KonfID{"dev-eu_dev-eu-1", "dev-asia_dev-asia-1"}},
+		{false, "dev-eu_dev-eu-1", true, []konf.KonfID{"dev-asia_dev-asia-1"}},
+		{false, "dev-eu_dev-eu-1", false, []konf.KonfID{"dev-asia_dev-asia-1"}},
+	}
+
+	for i, s := range steps {
+		var changed bool
+		var err error
+		if s.pin {
+			changed, err = sm.Pin(s.id)
+		} else {
+			changed, err = sm.Unpin(s.id)
+		}
+		if err != nil {
+			t.Fatalf("Step %d: exp no error, but got %v", i, err)
+		}
+		if changed != s.expChanged {
+			t.Errorf("Step %d: exp changed to be %t, got %t", i, s.expChanged, changed)
+		}
+
+		pins, err := sm.Pins()
+		if err != nil {
+			t.Fatalf("Step %d: exp no error, but got %v", i, err)
+		}
+		if !cmp.Equal(pins, s.expPins) {
+			t.Errorf("Step %d: exp pins %v, got %v", i, s.expPins, pins)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package store
+
+import (
+	"errors"
+	"io/fs"
+	"sort"
+	"time"
+
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/utils"
+	"github.com/spf13/afero"
+	k8s "k8s.io/client-go/tools/clientcmd/api/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// sessionDir is the dir in the state dir, which contains one file per session
+const sessionDir = "sessions"
+
+// Session describes a shell that uses an active konf. Its ID is the ID of the
+// active konf. It is either based on the PID of the shell and, where
+// available, its start time (see konf.IDFromProcess), or on a $KONF_SESSION
+// token (see konf.IDFromSessionToken). Tokens that do not start with a PID are
+// used as they are, so konf.ProcessFromID cannot tell whether their shell is
+// still running
+type Session struct {
+	ID konf.KonfID `json:"-"`
+	// Konfs are the IDs of the konfs in the session. The primary konf comes first
+	Konfs     []konf.KonfID `json:"konfs"`
+	Namespace string        `json:"namespace,omitempty"`
+	Started   time.Time     `json:"started"`
+	Shell     string        `json:"shell,omitempty"`
+	TTY       string        `json:"tty,omitempty"`
+	// Expires is the point in time the session expires. It is zero for
+	// sessions that never expire
+	Expires time.Time `json:"expires,omitzero"`
+}
+
+// Expired returns true if the session expires at or before now
+func (s *Session) Expired(now time.Time) bool {
+	return !s.Expires.IsZero() && !now.Before(s.Expires)
+}
+
+// sessionPathFromID returns the path of the metadata of the session with the
+// supplied id
+func (s *Storemanager) sessionPathFromID(id konf.KonfID) string {
+	return genIDPath(s.Statedir+"/"+sessionDir, string(id))
+}
+
+// Session returns the recorded metadata of the session with the supplied id.
+// If no metadata has been recorded, nil is returned
+func (s *Storemanager) Session(id konf.KonfID) (*Session, error) {
+	b, err := afero.ReadFile(s.Fs, s.sessionPathFromID(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ses := &Session{}
+	if err := yaml.Unmarshal(b, ses); err != nil {
+		return nil, err
+	}
+	ses.ID = id
+	return ses, nil
+}
+
+// WriteSession records the metadata of a session
+func (s *Storemanager) WriteSession(ses *Session) error {
+	b, err := yaml.Marshal(ses)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Fs.MkdirAll(s.Statedir+"/"+sessionDir, utils.KonfDirPerm); err != nil {
+		return err
+	}
+
+	return utils.WriteFileAtomic(s.Fs, s.sessionPathFromID(ses.ID), b, utils.KonfPerm)
+}
+
+// Sessions returns a session for every active konf, the oldest first. Konfs and
+// Namespace are always taken from the active konf, as they can be changed
+// after the session has been recorded. Everything else is only known for
+// sessions that have been recorded
+func (s *Storemanager) Sessions() ([]*Session, error) {
+	files, err := afero.ReadDir(s.Fs, s.Activedir)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []*Session{}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+
+		id := konf.IDFromFileInfo(f)
+		ses, err := s.Session(id)
+		if err != nil {
+			return nil, err
+		}
+		if ses == nil {
+			ses = &Session{ID: id}
+		}
+
+		b, err := afero.ReadFile(s.Fs, s.ActivePathFromID(id))
+		if err != nil {
+			return nil, err
+		}
+		var conf k8s.Config
+		// a broken active konf should not hide the session, so we fall back to what has been recorded
+		if err := yaml.Unmarshal(b, &conf); err == nil {
+			if ids := konf.IDsFromKubeconfig(&conf); len(ids) > 0 {
+				ses.Konfs = ids
+			}
+			if con, err := konf.CurrentContext(&conf); err == nil {
+				ses.Namespace = con.Context.Namespace
+			}
+		}
+
+		sessions = append(sessions, ses)
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		if !sessions[i].Started.Equal(sessions[j].Started) {
+			return sessions[i].Started.Before(sessions[j].Started)
+		}
+		return sessions[i].ID < sessions[j].ID
+	})
+	return sessions, nil
+}
+
+// RemoveSession revokes the session with the supplied id by removing its
+// active konf. The recorded metadata is removed as well
+func (s *Storemanager) RemoveSession(id konf.KonfID) error {
+	err := s.Fs.Remove(s.sessionPathFromID(id))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return s.Fs.Remove(s.ActivePathFromID(id))
+}
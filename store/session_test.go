@@ -0,0 +1,92 @@
+package store
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/spf13/afero"
+)
+
+func TestSessions(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sm := &Storemanager{Fs: fs, Activedir: "./konf/active", Statedir: "./konf/state"}
+	started := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// the active konf of session 1 cannot be parsed, so the recorded metadata is used
+	if err := afero.WriteFile(fs, sm.ActivePathFromID("1"), []byte("{"), 0600); err != nil {
+		t.Fatalf("Could not create active konf: %v", err)
+	}
+	if err := sm.WriteSession(&Session{ID: "1", Konfs: []konf.KonfID{"dev-eu_dev-eu-1"}, Namespace: "kube-system", Started: started}); err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	// session 2 has not been recorded
+	if err := afero.WriteFile(fs, sm.ActivePathFromID("2"), []byte(""), 0600); err != nil {
+		t.Fatalf("Could not create active konf: %v", err)
+	}
+
+	sessions, err := sm.Sessions()
+	if err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	exp := []*Session{
+		{ID: "2"},
+		{ID: "1", Konfs: []konf.KonfID{"dev-eu_dev-eu-1"}, Namespace: "kube-system", Started: started},
+	}
+	if !cmp.Equal(exp, sessions) {
+		t.Errorf("Exp sessions %v, got %v", exp, sessions)
+	}
+
+	if err := sm.RemoveSession("1"); err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	if ses, _ := sm.Session("1"); ses != nil {
+		t.Errorf("Exp metadata of session 1 to be removed, got %v", ses)
+	}
+	// sessions without metadata can be removed as well
+	if err := sm.RemoveSession("2"); err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	if sessions, _ := sm.Sessions(); len(sessions) != 0 {
+		t.Errorf("Exp no sessions, got %v", sessions)
+	}
+}
+
+func TestSessionExpired(t *testing.T) {
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tt := map[string]struct {
+		ses *Session
+		exp bool
+	}{
+		"never expires":   {&Session{}, false},
+		"not yet expired": {&Session{Expires: now.Add(time.Minute)}, false},
+		"expires now":     {&Session{Expires: now}, true},
+		"expired":         {&Session{Expires: now.Add(-time.Minute)}, true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if res := tc.ses.Expired(now); res != tc.exp {
+				t.Errorf("Exp expired to be %t, got %t", tc.exp, res)
+			}
+		})
+	}
+}
+
+func TestWriteSessionWithoutExpiry(t *testing.T) {
+	sm := &Storemanager{Fs: afero.NewMemMapFs(), Statedir: "./konf/state"}
+	if err := sm.WriteSession(&Session{ID: "1"}); err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+
+	b, err := afero.ReadFile(sm.Fs, sm.sessionPathFromID("1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "expires") {
+		t.Errorf("Exp sessions that never expire to be written without expiry, got %q", b)
+	}
+}
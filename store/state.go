@@ -0,0 +1,72 @@
+package store
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/simontheleg/konf-go/utils"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+)
+
+// statePathFromName returns the path of a state file in the state dir
+func (s *Storemanager) statePathFromName(name string) string {
+	return s.Statedir + "/" + name + ".yaml"
+}
+
+// readState unmarshals the state file with the supplied name into v. If the
+// file does not exist yet, v is left untouched and no error is returned. This
+// is because all state is optional and will be created on first usage
+func (s *Storemanager) readState(name string, v interface{}) error {
+	b, err := afero.ReadFile(s.Fs, s.statePathFromName(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return yaml.Unmarshal(b, v)
+}
+
+// writeState marshals v into the state file with the supplied name. The file
+// is replaced atomically, so that konf invocations in other shells never read
+// a partially written state
+func (s *Storemanager) writeState(name string, v interface{}) error {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Fs.MkdirAll(s.Statedir, utils.KonfDirPerm); err != nil {
+		return err
+	}
+
+	return utils.WriteFileAtomic(s.Fs, s.statePathFromName(name), b, utils.KonfPerm)
+}
+
+// updateState runs a read-modify-write on the state file with the supplied
+// name. The state is read into v, then update is called to modify it and
+// finally v is written back. The whole update holds a lock, so concurrent
+// updates from other shells are never lost. If update returns an error,
+// nothing is written
+func (s *Storemanager) updateState(name string, v interface{}, update func() error) error {
+	if err := s.Fs.MkdirAll(s.Statedir, utils.KonfDirPerm); err != nil {
+		return err
+	}
+
+	// the lock is hidden, so it is never mistaken for state
+	unlock, err := utils.LockFile(s.Fs, s.Statedir+"/."+name+".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := s.readState(name, v); err != nil {
+		return err
+	}
+	if err := update(); err != nil {
+		return err
+	}
+	return s.writeState(name, v)
+}
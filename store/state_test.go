@@ -0,0 +1,35 @@
+package store
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+func TestUpdateStateConcurrently(t *testing.T) {
+	sm := &Storemanager{Fs: afero.NewOsFs(), Statedir: t.TempDir()}
+
+	// every shell running 'konf set' records a usage at the same time
+	const shells = 20
+	var wg sync.WaitGroup
+	for i := 0; i < shells; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := sm.RecordUsage("dev-eu_dev-eu-1", time.Now()); err != nil {
+				t.Errorf("Exp no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	usage, err := sm.Usage()
+	if err != nil {
+		t.Fatalf("Exp no error, got %v", err)
+	}
+	if c := usage["dev-eu_dev-eu-1"].Count; c != shells {
+		t.Errorf("Exp all %d usages to be recorded, got %d", shells, c)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/simontheleg/konf-go/konf"
 	"github.com/simontheleg/konf-go/log"
@@ -21,11 +22,31 @@ type Metadata struct {
 	Context string
 	Cluster string
 	File    string
+	// Active is true if the konf is used in the current shell
+	Active bool
+	// Pinned is true if the konf has been pinned to the top of the selection
+	Pinned bool
+	// Namespace, Server, User, AuthMethod and CredentialExpiry are taken from
+	// the kubeconfig of the konf. CredentialExpiry is zero if it is unknown
+	Namespace        string
+	Server           string
+	User             string
+	AuthMethod       string
+	CredentialExpiry time.Time
+	// Aliases, Tags, Description and Protected are taken from the KonfMeta of the konf
+	Aliases     []string
+	Tags        []string
+	Description string
+	Protected   bool
+	// LastUsed is the last time the konf has been set. It is zero if the konf has never been set
+	LastUsed time.Time
 }
 
 type Storemanager struct {
 	Activedir      string
 	Storedir       string
+	Statedir       string
+	Trashdir       string
 	LatestKonfPath string
 	Fs             afero.Fs
 }
@@ -104,6 +125,15 @@ func (s *Storemanager) FetchKonfsForGlob(pattern string) ([]*Metadata, error) {
 		return nil, &NoMatch{Pattern: pattern}
 	}
 
+	metas, err := s.Metas()
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.Usage()
+	if err != nil {
+		return nil, err
+	}
+
 	out := []*Metadata{}
 	// TODO the logic of this loop should be extracted into the walkFn above to avoid looping twice
 	// TODO (possibly the walkfunction should also be extracted into its own function)
@@ -135,6 +165,26 @@ func (s *Storemanager) FetchKonfsForGlob(pattern string) ([]*Metadata, error) {
 		t.Context = kubeconf.Contexts[0].Name
 		t.Cluster = kubeconf.Clusters[0].Name
 		t.File = path
+		t.Namespace = kubeconf.Contexts[0].Context.Namespace
+		t.Server = kubeconf.Clusters[0].Cluster.Server
+		t.User = kubeconf.Contexts[0].Context.AuthInfo
+		var auth *k8s.AuthInfo
+		for i := range kubeconf.AuthInfos {
+			if kubeconf.AuthInfos[i].Name == t.User {
+				auth = &kubeconf.AuthInfos[i].AuthInfo
+			}
+		}
+		t.AuthMethod = konf.AuthMethod(auth)
+		t.CredentialExpiry, _ = konf.CredentialExpiry(auth)
+		if m, ok := metas[id]; ok {
+			t.Aliases = m.Aliases
+			t.Tags = m.Tags
+			t.Description = m.Description
+			t.Protected = m.Protected
+		}
+		if u, ok := usage[id]; ok {
+			t.LastUsed = u.LastUsed
+		}
 		out = append(out, &t)
 	}
 	return out, nil
@@ -157,6 +207,22 @@ func (s *Storemanager) WriteKonfToStore(konf *konf.Konfig) (storepath string, er
 	return storepath, nil
 }
 
+// ReadKonfFromStore reads the konf with the supplied id from the store
+func (s *Storemanager) ReadKonfFromStore(id konf.KonfID) (*konf.Konfig, error) {
+	b, err := afero.ReadFile(s.Fs, s.StorePathFromID(id))
+	if err != nil {
+		return nil, err
+	}
+
+	k := &konf.Konfig{Id: id}
+	err = yaml.Unmarshal(b, &k.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
 // ActivePathForID returns the active filepath for an id
 func (s *Storemanager) ActivePathFromID(id konf.KonfID) string {
 	return genIDPath(s.Activedir, string(id))
@@ -32,14 +32,22 @@ func TestFetchAllKonfs(t *testing.T) {
 			checkError: expNil,
 			expTableOut: []*Metadata{
 				{
-					Context: "dev-asia",
-					Cluster: "dev-asia-1",
-					File:    "./konf/store/dev-asia_dev-asia-1.yaml",
+					Context:    "dev-asia",
+					Cluster:    "dev-asia-1",
+					File:       "./konf/store/dev-asia_dev-asia-1.yaml",
+					Namespace:  "kube-public",
+					Server:     "https://10.1.1.0",
+					User:       "dev-asia",
+					AuthMethod: "none",
 				},
 				{
-					Context: "dev-eu",
-					Cluster: "dev-eu-1",
-					File:    "./konf/store/dev-eu_dev-eu-1.yaml",
+					Context:    "dev-eu",
+					Cluster:    "dev-eu-1",
+					File:       "./konf/store/dev-eu_dev-eu-1.yaml",
+					Namespace:  "kube-public",
+					Server:     "https://10.1.1.0",
+					User:       "dev-eu",
+					AuthMethod: "none",
 				},
 			},
 		},
@@ -58,9 +66,13 @@ func TestFetchAllKonfs(t *testing.T) {
 			checkError: expNil,
 			expTableOut: []*Metadata{
 				{
-					Context: "dev-eu",
-					Cluster: "dev-eu-1",
-					File:    "./konf/store/dev-eu_dev-eu-1.yaml",
+					Context:    "dev-eu",
+					Cluster:    "dev-eu-1",
+					File:       "./konf/store/dev-eu_dev-eu-1.yaml",
+					Namespace:  "kube-public",
+					Server:     "https://10.1.1.0",
+					User:       "dev-eu",
+					AuthMethod: "none",
 				},
 			},
 		},
@@ -69,9 +81,13 @@ func TestFetchAllKonfs(t *testing.T) {
 			checkError: expNil,
 			expTableOut: []*Metadata{
 				{
-					Context: "dev-eu",
-					Cluster: "dev-eu-1",
-					File:    "./konf/store/dev-eu_dev-eu-1.yaml",
+					Context:    "dev-eu",
+					Cluster:    "dev-eu-1",
+					File:       "./konf/store/dev-eu_dev-eu-1.yaml",
+					Namespace:  "kube-public",
+					Server:     "https://10.1.1.0",
+					User:       "dev-eu",
+					AuthMethod: "none",
 				},
 			},
 		},
@@ -121,14 +137,22 @@ func TestFetchAllKonfsCustomKonfDir(t *testing.T) {
 			checkError: expNil,
 			expTableOutput: []*Metadata{
 				{
-					Context: "dev-asia",
-					Cluster: "dev-asia-1",
-					File:    "konf/store/dev-asia_dev-asia-1.yaml",
+					Context:    "dev-asia",
+					Cluster:    "dev-asia-1",
+					File:       "konf/store/dev-asia_dev-asia-1.yaml",
+					Namespace:  "kube-public",
+					Server:     "https://10.1.1.0",
+					User:       "dev-asia",
+					AuthMethod: "none",
 				},
 				{
-					Context: "dev-eu",
-					Cluster: "dev-eu-1",
-					File:    "konf/store/dev-eu_dev-eu-1.yaml",
+					Context:    "dev-eu",
+					Cluster:    "dev-eu-1",
+					File:       "konf/store/dev-eu_dev-eu-1.yaml",
+					Namespace:  "kube-public",
+					Server:     "https://10.1.1.0",
+					User:       "dev-eu",
+					AuthMethod: "none",
 				},
 			},
 		},
@@ -147,9 +171,13 @@ func TestFetchAllKonfsCustomKonfDir(t *testing.T) {
 			checkError: expNil,
 			expTableOutput: []*Metadata{
 				{
-					Context: "dev-eu",
-					Cluster: "dev-eu-1",
-					File:    "konf/store/dev-eu_dev-eu-1.yaml",
+					Context:    "dev-eu",
+					Cluster:    "dev-eu-1",
+					File:       "konf/store/dev-eu_dev-eu-1.yaml",
+					Namespace:  "kube-public",
+					Server:     "https://10.1.1.0",
+					User:       "dev-eu",
+					AuthMethod: "none",
 				},
 			},
 		},
@@ -158,9 +186,13 @@ func TestFetchAllKonfsCustomKonfDir(t *testing.T) {
 			checkError: expNil,
 			expTableOutput: []*Metadata{
 				{
-					Context: "dev-eu",
-					Cluster: "dev-eu-1",
-					File:    "konf/store/dev-eu_dev-eu-1.yaml",
+					Context:    "dev-eu",
+					Cluster:    "dev-eu-1",
+					File:       "konf/store/dev-eu_dev-eu-1.yaml",
+					Namespace:  "kube-public",
+					Server:     "https://10.1.1.0",
+					User:       "dev-eu",
+					AuthMethod: "none",
 				},
 			},
 		},
@@ -203,9 +235,13 @@ func TestFetchKonfsForGlob(t *testing.T) {
 			glob:       "dev-eu*",
 			expTableOut: []*Metadata{
 				{
-					Context: "dev-eu",
-					Cluster: "dev-eu-1",
-					File:    "./konf/store/dev-eu_dev-eu-1.yaml",
+					Context:    "dev-eu",
+					Cluster:    "dev-eu-1",
+					File:       "./konf/store/dev-eu_dev-eu-1.yaml",
+					Namespace:  "kube-public",
+					Server:     "https://10.1.1.0",
+					User:       "dev-eu",
+					AuthMethod: "none",
 				},
 			},
 		},
@@ -215,9 +251,13 @@ func TestFetchKonfsForGlob(t *testing.T) {
 			glob:       "dev-eu_dev-eu-1",
 			expTableOut: []*Metadata{
 				{
-					Context: "dev-eu",
-					Cluster: "dev-eu-1",
-					File:    "./konf/store/dev-eu_dev-eu-1.yaml",
+					Context:    "dev-eu",
+					Cluster:    "dev-eu-1",
+					File:       "./konf/store/dev-eu_dev-eu-1.yaml",
+					Namespace:  "kube-public",
+					Server:     "https://10.1.1.0",
+					User:       "dev-eu",
+					AuthMethod: "none",
 				},
 			},
 		},
@@ -227,9 +267,13 @@ func TestFetchKonfsForGlob(t *testing.T) {
 			glob:       "dev-asia*",
 			expTableOut: []*Metadata{
 				{
-					Context: "dev-asia",
-					Cluster: "dev-asia-1",
-					File:    "./konf/store/dev-asia_dev-asia-1.yaml",
+					Context:    "dev-asia",
+					Cluster:    "dev-asia-1",
+					File:       "./konf/store/dev-asia_dev-asia-1.yaml",
+					Namespace:  "kube-public",
+					Server:     "https://10.1.1.0",
+					User:       "dev-asia",
+					AuthMethod: "none",
 				},
 			},
 		},
@@ -239,9 +283,13 @@ func TestFetchKonfsForGlob(t *testing.T) {
 			glob:       "dev-eu*",
 			expTableOut: []*Metadata{
 				{
-					Context: "dev-eu",
-					Cluster: "dev-eu-1",
-					File:    "./konf/store/dev-eu_dev-eu-1.yaml",
+					Context:    "dev-eu",
+					Cluster:    "dev-eu-1",
+					File:       "./konf/store/dev-eu_dev-eu-1.yaml",
+					Namespace:  "kube-public",
+					Server:     "https://10.1.1.0",
+					User:       "dev-eu",
+					AuthMethod: "none",
 				},
 			},
 		},
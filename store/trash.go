@@ -0,0 +1,184 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/simontheleg/konf-go/utils"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+)
+
+// files of a single entry in the trash dir
+const (
+	trashKonfFile = "konf.yaml"
+	trashInfoFile = "info.yaml"
+)
+
+// TrashedKonf describes a konf that has been moved to the trash. Besides the
+// kubeconfig itself, the trash keeps everything konf knew about the konf, so
+// it can be restored completely
+type TrashedKonf struct {
+	ID        konf.KonfID `json:"id"`
+	DeletedAt time.Time   `json:"deletedAt"`
+	Pinned    bool        `json:"pinned,omitempty"`
+	Meta      *KonfMeta   `json:"meta,omitempty"`
+
+	// Name is the name of the entry in the trash dir. The same konf can be
+	// deleted multiple times, so the ID alone is not unique
+	Name string `json:"-"`
+}
+
+// trashPathFromName returns the path of an entry in the trash dir
+func (s *Storemanager) trashPathFromName(name string) string {
+	return s.Trashdir + "/" + name
+}
+
+// Trash moves the konf with the supplied id from the store into the trash. Its
+// metadata and pin are moved along with it, so its aliases can be used by
+// other konfs in the meantime
+func (s *Storemanager) Trash(id konf.KonfID, now time.Time) (*TrashedKonf, error) {
+	storePath := s.StorePathFromID(id)
+	b, err := afero.ReadFile(s.Fs, storePath)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := s.Meta(id)
+	if err != nil {
+		return nil, err
+	}
+	pins, err := s.Pins()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &TrashedKonf{
+		ID:        id,
+		DeletedAt: now,
+		Name:      strconv.FormatInt(now.UnixNano(), 10) + "_" + string(id),
+	}
+	if !meta.IsEmpty() {
+		t.Meta = meta
+	}
+	t.Pinned = slices.Contains(pins, id)
+
+	info, err := yaml.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+
+	// the konf is only removed from the store once it is safely in the trash
+	dir := s.trashPathFromName(t.Name)
+	if err := s.Fs.MkdirAll(dir, utils.KonfDirPerm); err != nil {
+		return nil, err
+	}
+	if err := afero.WriteFile(s.Fs, dir+"/"+trashKonfFile, b, utils.KonfPerm); err != nil {
+		return nil, err
+	}
+	if err := afero.WriteFile(s.Fs, dir+"/"+trashInfoFile, info, utils.KonfPerm); err != nil {
+		return nil, err
+	}
+	if err := s.Fs.Remove(storePath); err != nil {
+		return nil, err
+	}
+
+	if err := s.SetMeta(id, &KonfMeta{}); err != nil {
+		return nil, err
+	}
+	if _, err := s.Unpin(id); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// TrashedKonfs returns all konfs in the trash, the most recently deleted first
+func (s *Storemanager) TrashedKonfs() ([]*TrashedKonf, error) {
+	entries, err := afero.ReadDir(s.Fs, s.Trashdir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []*TrashedKonf{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	trashed := []*TrashedKonf{}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		b, err := afero.ReadFile(s.Fs, s.trashPathFromName(e.Name())+"/"+trashInfoFile)
+		if err != nil {
+			return nil, err
+		}
+		t := &TrashedKonf{}
+		if err := yaml.Unmarshal(b, t); err != nil {
+			return nil, fmt.Errorf("could not read trashed konf %q: %v", e.Name(), err)
+		}
+		t.Name = e.Name()
+		trashed = append(trashed, t)
+	}
+
+	sort.SliceStable(trashed, func(i, j int) bool { return trashed[i].DeletedAt.After(trashed[j].DeletedAt) })
+	return trashed, nil
+}
+
+// Restore moves a konf from the trash back into the store, together with its
+// metadata and pin. A konf that has been re-imported in the meantime is never
+// overwritten. Aliases that have been given to other konfs in the meantime
+// are dropped and returned
+func (s *Storemanager) Restore(t *TrashedKonf) ([]string, error) {
+	storePath := s.StorePathFromID(t.ID)
+	if _, err := s.Fs.Stat(storePath); err == nil {
+		return nil, fmt.Errorf("konf %q already exists in the store", t.ID)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	dir := s.trashPathFromName(t.Name)
+	b, err := afero.ReadFile(s.Fs, dir+"/"+trashKonfFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := afero.WriteFile(s.Fs, storePath, b, utils.KonfPerm); err != nil {
+		return nil, err
+	}
+
+	var dropped []string
+	if !t.Meta.IsEmpty() {
+		meta := *t.Meta
+		meta.Aliases = nil
+		for _, a := range t.Meta.Aliases {
+			if _, taken, err := s.IDForAlias(a); err != nil {
+				return nil, err
+			} else if taken {
+				dropped = append(dropped, a)
+				continue
+			}
+			meta.Aliases = append(meta.Aliases, a)
+		}
+		if err := s.SetMeta(t.ID, &meta); err != nil {
+			return nil, err
+		}
+	}
+	if t.Pinned {
+		if _, err := s.Pin(t.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return dropped, s.Fs.RemoveAll(dir)
+}
+
+// RemoveTrashed permanently deletes a konf from the trash
+func (s *Storemanager) RemoveTrashed(t *TrashedKonf) error {
+	return s.Fs.RemoveAll(s.trashPathFromName(t.Name))
+}
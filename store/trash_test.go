@@ -0,0 +1,109 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/spf13/afero"
+)
+
+func TestTrashAndRestore(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sm := &Storemanager{Fs: fs, Storedir: "./konf/store", Statedir: "./konf/state", Trashdir: "./konf/trash"}
+	eu := konf.KonfID("dev-eu_dev-eu-1")
+	asia := konf.KonfID("dev-asia_dev-asia-1")
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, id := range []konf.KonfID{eu, asia} {
+		if err := afero.WriteFile(fs, sm.StorePathFromID(id), []byte("kubeconfig of "+id), 0600); err != nil {
+			t.Fatalf("Could not create konf: %v", err)
+		}
+	}
+	if err := sm.SetMeta(eu, &KonfMeta{Aliases: []string{"eu", "dev"}, Tags: []string{"dev"}}); err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	if _, err := sm.Pin(eu); err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+
+	if _, err := sm.Trash(asia, now); err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	if _, err := sm.Trash(eu, now.Add(time.Hour)); err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	if _, err := sm.Trash(eu, now); err == nil {
+		t.Errorf("Exp konf %q to not be trashed twice", eu)
+	}
+
+	if _, err := fs.Stat(sm.StorePathFromID(eu)); err == nil {
+		t.Errorf("Exp konf %q to be removed from the store", eu)
+	}
+	if m, _ := sm.Meta(eu); !m.IsEmpty() {
+		t.Errorf("Exp metadata of %q to be moved to the trash, got %v", eu, m)
+	}
+	if pins, _ := sm.Pins(); len(pins) != 0 {
+		t.Errorf("Exp pin of %q to be moved to the trash, got %v", eu, pins)
+	}
+
+	trashed, err := sm.TrashedKonfs()
+	if err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	exp := []*TrashedKonf{
+		{ID: eu, DeletedAt: now.Add(time.Hour), Pinned: true, Meta: &KonfMeta{Aliases: []string{"eu", "dev"}, Tags: []string{"dev"}}, Name: "1641042000000000000_dev-eu_dev-eu-1"},
+		{ID: asia, DeletedAt: now, Name: "1641038400000000000_dev-asia_dev-asia-1"},
+	}
+	if !cmp.Equal(exp, trashed) {
+		t.Errorf("Exp trashed konfs to be %v, got %v", exp, trashed)
+	}
+
+	// in the meantime another konf has taken over one of the aliases
+	if err := sm.SetMeta(asia, &KonfMeta{Aliases: []string{"dev"}}); err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	dropped, err := sm.Restore(trashed[0])
+	if err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	if !cmp.Equal([]string{"dev"}, dropped) {
+		t.Errorf("Exp alias %q to be dropped, got %v", "dev", dropped)
+	}
+
+	b, err := afero.ReadFile(fs, sm.StorePathFromID(eu))
+	if err != nil || string(b) != "kubeconfig of "+string(eu) {
+		t.Errorf("Exp konf %q to be restored, got %q (err: %v)", eu, b, err)
+	}
+	if m, _ := sm.Meta(eu); !cmp.Equal(m, &KonfMeta{Aliases: []string{"eu"}, Tags: []string{"dev"}}) {
+		t.Errorf("Exp metadata of %q to be restored, got %v", eu, m)
+	}
+	if pins, _ := sm.Pins(); !cmp.Equal(pins, []konf.KonfID{eu}) {
+		t.Errorf("Exp %q to be pinned again, got %v", eu, pins)
+	}
+
+	// a konf that exists in the store is never overwritten
+	if err := afero.WriteFile(fs, sm.StorePathFromID(asia), []byte("re-imported"), 0600); err != nil {
+		t.Fatalf("Could not create konf: %v", err)
+	}
+	if _, err := sm.Restore(trashed[1]); err == nil {
+		t.Errorf("Exp restoring %q to fail, as it exists in the store", asia)
+	}
+
+	if err := sm.RemoveTrashed(trashed[1]); err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	if trashed, _ := sm.TrashedKonfs(); len(trashed) != 0 {
+		t.Errorf("Exp trash to be empty, got %v", trashed)
+	}
+}
+
+func TestTrashedKonfsWithoutTrash(t *testing.T) {
+	sm := &Storemanager{Fs: afero.NewMemMapFs(), Trashdir: "./konf/trash"}
+
+	trashed, err := sm.TrashedKonfs()
+	if err != nil || len(trashed) != 0 {
+		t.Errorf("Exp an empty trash, got %v (err: %v)", trashed, err)
+	}
+}
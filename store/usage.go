@@ -0,0 +1,72 @@
+package store
+
+import (
+	"math"
+	"time"
+
+	"github.com/simontheleg/konf-go/konf"
+)
+
+const usageState = "usage"
+
+// frecencyHalfLife is the time after which the score of a konf has lost half
+// of its weight
+const frecencyHalfLife = 3 * 24 * time.Hour
+
+// Usage describes how often and how recently a konf has been used
+type Usage struct {
+	Count    int       `json:"count"`
+	LastUsed time.Time `json:"lastUsed"`
+	// Score is the frecency at LastUsed. It is updated on every usage, see
+	// Frecency
+	Score float64 `json:"score,omitempty"`
+}
+
+// Frecency combines the frequency and the recency of the usage of a konf into
+// a single score. Every usage adds one to the score. Starting one hour after
+// the last usage, the whole score decays and halves every frecencyHalfLife,
+// so that konfs that were only used heavily a long time ago do not stay on
+// top forever
+func (u *Usage) Frecency(now time.Time) float64 {
+	if u == nil || u.Count == 0 {
+		return 0
+	}
+
+	score := u.Score
+	// usage recorded by older versions of konf only has a count
+	if score == 0 {
+		score = float64(u.Count)
+	}
+
+	age := now.Sub(u.LastUsed)
+	if age < time.Hour {
+		return score
+	}
+	return score * math.Pow(0.5, float64(age-time.Hour)/float64(frecencyHalfLife))
+}
+
+// Usage returns the usage of all konfs that have been set at least once
+func (s *Storemanager) Usage() (map[konf.KonfID]*Usage, error) {
+	usage := map[konf.KonfID]*Usage{}
+	if err := s.readState(usageState, &usage); err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// RecordUsage increases the usage count of a konf and sets its last usage to t
+func (s *Storemanager) RecordUsage(id konf.KonfID, t time.Time) error {
+	usage := map[konf.KonfID]*Usage{}
+	return s.updateState(usageState, &usage, func() error {
+		u, ok := usage[id]
+		if !ok {
+			u = &Usage{}
+			usage[id] = u
+		}
+		u.Score = u.Frecency(t) + 1
+		u.Count++
+		u.LastUsed = t.UTC()
+		return nil
+	})
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/simontheleg/konf-go/konf"
+	"github.com/spf13/afero"
+)
+
+func TestRecordUsage(t *testing.T) {
+	sm := &Storemanager{Fs: afero.NewMemMapFs(), Statedir: "./konf/state"}
+	eu := konf.KonfID("dev-eu_dev-eu-1")
+	asia := konf.KonfID("dev-asia_dev-asia-1")
+	first := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+
+	for _, u := range []struct {
+		id konf.KonfID
+		t  time.Time
+	}{{eu, first}, {asia, first}, {eu, second}} {
+		if err := sm.RecordUsage(u.id, u.t); err != nil {
+			t.Fatalf("Exp no error, but got %v", err)
+		}
+	}
+
+	usage, err := sm.Usage()
+	if err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+
+	if usage[eu].Count != 2 || !usage[eu].LastUsed.Equal(second) {
+		t.Errorf("Exp %q to be used 2 times, last at %s, but got %d times, last at %s", eu, second, usage[eu].Count, usage[eu].LastUsed)
+	}
+	if usage[asia].Count != 1 || !usage[asia].LastUsed.Equal(first) {
+		t.Errorf("Exp %q to be used 1 time, last at %s, but got %d times, last at %s", asia, first, usage[asia].Count, usage[asia].LastUsed)
+	}
+}
+
+func TestFrecency(t *testing.T) {
+	now := time.Date(2022, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	tt := map[string]struct {
+		u   *Usage
+		exp float64
+	}{
+		"never used":                {nil, 0},
+		"used within the last hour": {&Usage{Count: 3, Score: 3, LastUsed: now.Add(-10 * time.Minute)}, 3},
+		"one half-life ago":         {&Usage{Count: 3, Score: 3, LastUsed: now.Add(-frecencyHalfLife - time.Hour)}, 1.5},
+		"two half-lives ago":        {&Usage{Count: 3, Score: 3, LastUsed: now.Add(-2*frecencyHalfLife - time.Hour)}, 0.75},
+		"score has decayed before":  {&Usage{Count: 3, Score: 1.5, LastUsed: now}, 1.5},
+		"recorded by older version": {&Usage{Count: 3, LastUsed: now.Add(-frecencyHalfLife - time.Hour)}, 1.5},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if res := tc.u.Frecency(now); res != tc.exp {
+				t.Errorf("Exp score %v, got %v", tc.exp, res)
+			}
+		})
+	}
+}
+
+func TestFrecencyOfOldHeavyUsage(t *testing.T) {
+	sm := &Storemanager{Fs: afero.NewMemMapFs(), Statedir: "./konf/state"}
+	now := time.Date(2022, 2, 1, 12, 0, 0, 0, time.UTC)
+	old := konf.KonfID("old_old-1")
+	recent := konf.KonfID("recent_recent-1")
+
+	// the old konf has been used 1000 times a month ago, the recent one 10 times in the last hour
+	monthAgo := now.Add(-30 * 24 * time.Hour)
+	for i := 0; i < 1000; i++ {
+		if err := sm.RecordUsage(old, monthAgo.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatalf("Exp no error, but got %v", err)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		if err := sm.RecordUsage(recent, now.Add(-time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("Exp no error, but got %v", err)
+		}
+	}
+
+	usage, err := sm.Usage()
+	if err != nil {
+		t.Fatalf("Exp no error, but got %v", err)
+	}
+	if o, r := usage[old].Frecency(now), usage[recent].Frecency(now); o >= r {
+		t.Errorf("Exp recent konf to score higher than old konf, got %v for recent and %v for old", r, o)
+	}
+}
@@ -13,7 +13,7 @@ const KonfPerm fs.FileMode = 0600 // based on the standard file-permissions for
 // KonfDirPerm describes the file-permissions for konf directories
 const KonfDirPerm fs.FileMode = 0700 // needed so we can create folders inside
 
-// EnsureDir makes sure that konf store and active dirs exist
+// EnsureDir makes sure that konf store, active and state dirs exist
 func EnsureDir(f afero.Fs) error {
 
 	err := f.MkdirAll(config.StoreDir()+"/", KonfDirPerm)
@@ -25,6 +25,10 @@ func EnsureDir(f afero.Fs) error {
 	if err != nil {
 		return err
 	}
+	err = f.MkdirAll(config.StateDir()+"/", KonfDirPerm)
+	if err != nil {
+		return err
+	}
 
 	return nil
 }
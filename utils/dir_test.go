@@ -35,4 +35,12 @@ func TestEnsureDir(t *testing.T) {
 	if r.IsDir() != true {
 		t.Errorf("Expected %s to be a dir, but it is not %q", r.Name(), r)
 	}
+
+	r, err = f.Stat("./konf/state")
+	if err != nil {
+		t.Errorf("Could not run stat, please check tests: %v", err)
+	}
+	if r.IsDir() != true {
+		t.Errorf("Expected %s to be a dir, but it is not %q", r.Name(), r)
+	}
 }
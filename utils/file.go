@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// WriteFileAtomic works like afero.WriteFile, but makes sure that readers
+// never see a partially written file. This is achieved by writing to a
+// temporary file in the same directory first and then renaming it to the
+// target. The temporary file is hidden, so konf will never pick it up as a
+// konf
+func WriteFileAtomic(f afero.Fs, path string, data []byte, perm fs.FileMode) error {
+	tmp, err := afero.TempFile(f, filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	// in case anything goes wrong, we do not want to leave the tmp file behind. After
+	// a successful rename this is a no-op
+	defer f.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := f.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+
+	return f.Rename(tmp.Name(), path)
+}
+
+// lockRetryInterval and lockTimeout control how long LockFile waits for a
+// lock held by another konf
+var (
+	lockRetryInterval = 10 * time.Millisecond
+	lockTimeout       = 5 * time.Second
+)
+
+// staleLockAge is the age after which a lock is considered to be left behind
+// by a konf that has crashed. Locks are only held for a single
+// read-modify-write, so any lock older than this cannot be in use anymore
+const staleLockAge = 10 * time.Second
+
+// LockFile acquires an exclusive lock by creating the file at path. This
+// works on any afero.Fs and, unlike flock, does not depend on the OS. If
+// another konf holds the lock, LockFile waits for it to be released. The
+// returned func releases the lock
+func LockFile(f afero.Fs, path string) (unlock func() error, err error) {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		l, err := f.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, KonfPerm)
+		if err == nil {
+			if err := l.Close(); err != nil {
+				return nil, err
+			}
+			return func() error { return f.Remove(path) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+
+		if fi, err := f.Stat(path); err == nil && time.Since(fi.ModTime()) > staleLockAge {
+			if err := f.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for lock %q. If no other konf is running, remove it manually", path)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
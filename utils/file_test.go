@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	f := afero.NewMemMapFs()
+	dir := "./konf/store"
+	path := dir + "/dev-eu_dev-eu-1.yaml"
+	if err := f.MkdirAll(dir, KonfDirPerm); err != nil {
+		t.Fatalf("Could not create dir, please check test code: %v", err)
+	}
+	if err := afero.WriteFile(f, path, []byte("old"), KonfPerm); err != nil {
+		t.Fatalf("Could not create file, please check test code: %v", err)
+	}
+
+	err := WriteFileAtomic(f, path, []byte("new"), KonfPerm)
+	if err != nil {
+		t.Fatalf("Unexpected error while running WriteFileAtomic: %q", err)
+	}
+
+	b, err := afero.ReadFile(f, path)
+	if err != nil {
+		t.Fatalf("Could not read file: %v", err)
+	}
+	if string(b) != "new" {
+		t.Errorf("Exp content %q, got %q", "new", string(b))
+	}
+
+	fi, err := f.Stat(path)
+	if err != nil {
+		t.Fatalf("Could not stat file: %v", err)
+	}
+	if fi.Mode().Perm() != KonfPerm {
+		t.Errorf("Exp perm %v, got %v", KonfPerm, fi.Mode().Perm())
+	}
+
+	// no tmp files should be left behind
+	fis, err := afero.ReadDir(f, dir)
+	if err != nil {
+		t.Fatalf("Could not read dir: %v", err)
+	}
+	if len(fis) != 1 {
+		t.Errorf("Exp exactly one file in %q, got %d", dir, len(fis))
+	}
+}
+
+func TestLockFile(t *testing.T) {
+	lockTimeout = 50 * time.Millisecond
+	defer func() { lockTimeout = 5 * time.Second }()
+
+	f := afero.NewMemMapFs()
+	path := "./konf/state/.usage.lock"
+
+	unlock, err := LockFile(f, path)
+	if err != nil {
+		t.Fatalf("Exp no error when acquiring a free lock, got %v", err)
+	}
+
+	if _, err := LockFile(f, path); err == nil {
+		t.Errorf("Exp an error when acquiring a held lock, got none")
+	}
+
+	if err := unlock(); err != nil {
+		t.Fatalf("Exp no error when releasing the lock, got %v", err)
+	}
+	unlock, err = LockFile(f, path)
+	if err != nil {
+		t.Fatalf("Exp no error when acquiring a released lock, got %v", err)
+	}
+	unlock()
+
+	// a lock left behind by a crashed konf must not block forever
+	if err := afero.WriteFile(f, path, []byte{}, KonfPerm); err != nil {
+		t.Fatalf("Could not create lock, please check test code: %v", err)
+	}
+	old := time.Now().Add(-2 * staleLockAge)
+	if err := f.Chtimes(path, old, old); err != nil {
+		t.Fatalf("Could not age lock, please check test code: %v", err)
+	}
+	if _, err := LockFile(f, path); err != nil {
+		t.Errorf("Exp stale lock to be taken over, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// procDir is the mount point of procfs
+var procDir = "/proc"
+
+// ProcessStartTime returns the point in time the process with the supplied pid
+// has been started, in clock ticks since boot. Together with the pid it
+// identifies a process uniquely, even if the pid is reused by the operating
+// system later on.
+//
+// The start time can only be determined on systems with procfs, like Linux.
+// On all other systems an empty string is returned. If the process does not
+// exist, an error wrapping fs.ErrNotExist is returned
+func ProcessStartTime(pid int) (string, error) {
+	if _, err := os.Stat(procDir + "/self/stat"); err != nil {
+		return "", nil
+	}
+
+	b, err := os.ReadFile(fmt.Sprintf("%s/%d/stat", procDir, pid))
+	if err != nil {
+		return "", err
+	}
+
+	return parseStartTime(string(b))
+}
+
+// parseStartTime extracts the start time from the content of /proc/<pid>/stat.
+// See 'man 5 proc' for its format
+func parseStartTime(stat string) (string, error) {
+	// the second field is the name of the executable in parentheses, which can contain spaces and parentheses itself
+	i := strings.LastIndex(stat, ")")
+	if i < 0 {
+		return "", fmt.Errorf("could not parse process stat %q", stat)
+	}
+
+	// starttime is the 22nd field, which is the 20th after the executable name
+	fields := strings.Fields(stat[i+1:])
+	if len(fields) < 20 {
+		return "", fmt.Errorf("could not parse process stat %q", stat)
+	}
+
+	return fields[19], nil
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestParseStartTime(t *testing.T) {
+	tt := map[string]struct {
+		stat     string
+		expStart string
+		expErr   bool
+	}{
+		"regular process": {
+			"1234 (zsh) S 1 1234 1234 34816 1234 4194304 1 2 3 4 5 6 7 8 20 0 1 0 987654 1234 56 18446744073709551615",
+			"987654",
+			false,
+		},
+		"executable with spaces and parentheses": {
+			"1234 (my (weird) shell) S 1 1234 1234 34816 1234 4194304 1 2 3 4 5 6 7 8 20 0 1 0 987654 1234 56",
+			"987654",
+			false,
+		},
+		"truncated": {
+			"1234 (zsh) S 1 1234",
+			"",
+			true,
+		},
+		"no executable": {
+			"1234",
+			"",
+			true,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			start, err := parseStartTime(tc.stat)
+			if (err != nil) != tc.expErr {
+				t.Errorf("Exp error to be %t, got %v", tc.expErr, err)
+			}
+			if start != tc.expStart {
+				t.Errorf("Exp start time %q, got %q", tc.expStart, start)
+			}
+		})
+	}
+}
+
+func TestProcessStartTime(t *testing.T) {
+	if _, err := os.Stat(procDir + "/self/stat"); err != nil {
+		t.Skip("Skipping TestProcessStartTime, as procfs is not available")
+	}
+
+	start, err := ProcessStartTime(os.Getpid())
+	if err != nil || start == "" {
+		t.Fatalf("Exp start time of the current process, got %q (err: %v)", start, err)
+	}
+	if again, _ := ProcessStartTime(os.Getpid()); again != start {
+		t.Errorf("Exp start time to be stable, got %q and %q", start, again)
+	}
+
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ProcessStartTime(cmd.Process.Pid); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Exp start time of an exited process to not exist, got %v", err)
+	}
+}